@@ -0,0 +1,173 @@
+package ecs
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// archetype stores all entities that have exactly the same set of component types. Components are kept in one
+// contiguous column per type, and the components of an entity live at the same row in every column
+type archetype struct {
+	// types is the sorted set of IDs of the component types of the archetype
+	types []ComponentID
+	// signature holds the same IDs as types
+	signature Signature
+	// columns holds the component data of each type, in the same order as types
+	columns []column
+	// entities holds the entity stored at each row
+	entities []Entity
+	// edges caches the archetypes reached by adding or removing a component type
+	edges map[ComponentID]*archetypeEdge
+}
+
+type archetypeEdge struct {
+	add    *archetype
+	remove *archetype
+}
+
+func newArchetype(types []ComponentID, columns []column) *archetype {
+	return &archetype{
+		types:     types,
+		signature: NewSignature(types...),
+		columns:   columns,
+		entities:  make([]Entity, 0),
+		edges:     make(map[ComponentID]*archetypeEdge),
+	}
+}
+
+// archetypeKey returns the key of a sorted set of component type IDs
+func archetypeKey(types []ComponentID) string {
+	key := make([]byte, 0, len(types)*4)
+	for _, t := range types {
+		key = binary.LittleEndian.AppendUint32(key, uint32(t))
+	}
+	return string(key)
+}
+
+// column returns the index of the column holding components of the given type, or -1 if the archetype does not have it
+func (a *archetype) column(id ComponentID) int {
+	i, ok := slices.BinarySearch(a.types, id)
+	if !ok {
+		return -1
+	}
+	return i
+}
+
+// reserve makes room for n more rows without reallocating
+func (a *archetype) reserve(n int) {
+	a.entities = slices.Grow(a.entities, n)
+	for _, c := range a.columns {
+		c.reserve(n)
+	}
+}
+
+// removeRow removes the row by moving the last row into it. It returns the entity that now occupies the row and true,
+// or false if the removed row was the last one
+func (a *archetype) removeRow(row int) (Entity, bool) {
+	last := len(a.entities) - 1
+	for _, c := range a.columns {
+		c.removeRow(row)
+	}
+	a.entities[row] = a.entities[last]
+	a.entities = a.entities[:last]
+	if row == last {
+		return 0, false
+	}
+	return a.entities[row], true
+}
+
+/** Archetype management **/
+
+// getArchetype returns the archetype for the sorted set of component types, creating it if it does not exist yet
+func (m *Manager) getArchetype(types []ComponentID) *archetype {
+	key := archetypeKey(types)
+	if a, ok := m.archetypeIndex[key]; ok {
+		return a
+	}
+
+	columns := make([]column, len(types))
+	for i, t := range types {
+		columns[i] = m.registry.components[t].newColumn()
+	}
+	a := newArchetype(types, columns)
+	m.archetypes = append(m.archetypes, a)
+	m.archetypeIndex[key] = a
+	for _, t := range types {
+		m.componentIndex[t] = append(m.componentIndex[t], a)
+	}
+	m.cacheArchetype(a)
+	return a
+}
+
+func (m *Manager) edge(a *archetype, id ComponentID) *archetypeEdge {
+	e, ok := a.edges[id]
+	if !ok {
+		e = &archetypeEdge{}
+		a.edges[id] = e
+	}
+	return e
+}
+
+// archetypeWith returns the archetype with the component types of a plus the given type
+func (m *Manager) archetypeWith(a *archetype, id ComponentID) *archetype {
+	e := m.edge(a, id)
+	if e.add == nil {
+		types := slices.Clone(a.types)
+		i, _ := slices.BinarySearch(types, id)
+		e.add = m.getArchetype(slices.Insert(types, i, id))
+	}
+	return e.add
+}
+
+// archetypeWithout returns the archetype with the component types of a minus the given type
+func (m *Manager) archetypeWithout(a *archetype, id ComponentID) *archetype {
+	e := m.edge(a, id)
+	if e.remove == nil {
+		types := slices.Clone(a.types)
+		i, _ := slices.BinarySearch(types, id)
+		e.remove = m.getArchetype(slices.Delete(types, i, i+1))
+	}
+	return e.remove
+}
+
+// appendEntity adds a row for the entity at the end of the archetype, holding zero values in every column
+func (m *Manager) appendEntity(entity Entity, a *archetype) {
+	record := &m.entities[entity.Index()]
+	record.archetype = a
+	record.row = len(a.entities)
+	a.entities = append(a.entities, entity)
+	for _, c := range a.columns {
+		c.appendZero()
+	}
+}
+
+// removeEntity removes the row of the entity from its archetype and fixes up the record of the entity moved into it
+func (m *Manager) removeEntity(entity Entity) {
+	record := &m.entities[entity.Index()]
+	if moved, ok := record.archetype.removeRow(record.row); ok {
+		m.entities[moved.Index()].row = record.row
+	}
+	record.archetype = nil
+	record.row = 0
+}
+
+// moveEntity moves the entity to the target archetype, copying over the components both archetypes have in common
+func (m *Manager) moveEntity(entity Entity, target *archetype) {
+	record := m.entities[entity.Index()]
+	source := record.archetype
+
+	for i, t := range target.types {
+		if j := source.column(t); j >= 0 {
+			target.columns[i].appendFrom(source.columns[j], record.row)
+		} else {
+			target.columns[i].appendZero()
+		}
+	}
+	target.entities = append(target.entities, entity)
+	m.entities[entity.Index()].archetype = target
+	m.entities[entity.Index()].row = len(target.entities) - 1
+
+	if moved, ok := source.removeRow(record.row); ok {
+		m.entities[moved.Index()].row = record.row
+	}
+}
@@ -0,0 +1,87 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Archetype_MoveEntity(t *testing.T) {
+	m := NewManager()
+	e := m.CreateEntity()
+	t.Log("New entity is stored in the empty archetype")
+	{
+		require.Same(t, m.archetypes[0], m.entities[e.Index()].archetype)
+		require.Empty(t, m.archetypes[0].types)
+	}
+
+	t.Log("Adding components moves the entity between archetypes - keeps existing components")
+	{
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}}))
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}}))
+		a := m.entities[e.Index()].archetype
+		stringID, err := m.Registry().ID(TestComponentStringKey)
+		require.NoError(t, err)
+		numberID, err := m.Registry().ID(TestComponentNumberKey)
+		require.NoError(t, err)
+		require.Equal(t, []ComponentID{stringID, numberID}, a.types)
+		require.Len(t, a.entities, 1)
+		require.Empty(t, m.archetypes[0].entities)
+
+		c, err := m.GetComponentOfEntity(e, TestComponentStringKey)
+		require.NoError(t, err)
+		require.Equal(t, "Hello", c.Data.(TestComponentString).content)
+	}
+
+	t.Log("Overwriting a component - stays in the same archetype")
+	{
+		a := m.entities[e.Index()].archetype
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}}))
+		require.Same(t, a, m.entities[e.Index()].archetype)
+		c, err := m.GetComponentOfEntity(e, TestComponentNumberKey)
+		require.NoError(t, err)
+		require.Equal(t, 43, c.Data.(TestComponentNumber).content)
+	}
+
+	t.Log("Removing a component - reuses the cached archetype")
+	{
+		archetypes := len(m.archetypes)
+		require.NoError(t, m.DeleteComponentOfEntity(e, TestComponentNumberKey))
+		require.Len(t, m.archetypes, archetypes)
+		stringID, err := m.Registry().ID(TestComponentStringKey)
+		require.NoError(t, err)
+		require.Equal(t, []ComponentID{stringID}, m.entities[e.Index()].archetype.types)
+	}
+}
+
+func Test_Archetype_RemoveRow(t *testing.T) {
+	m := NewManager()
+	entities := make([]Entity, 10)
+	for i := range entities {
+		entities[i] = m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(entities[i], Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}}))
+	}
+
+	t.Log("Deleting entities from the middle of an archetype - keeps the components of the other entities")
+	{
+		require.NoError(t, m.DeleteEntity(entities[2]))
+		require.NoError(t, m.DeleteComponentOfEntity(entities[5], TestComponentNumberKey))
+		require.NoError(t, m.DeleteEntity(entities[9]))
+
+		for i, e := range entities {
+			if i == 2 || i == 5 || i == 9 {
+				continue
+			}
+			c, err := m.GetComponentOfEntity(e, TestComponentNumberKey)
+			require.NoError(t, err)
+			require.Equal(t, i, c.Data.(TestComponentNumber).content)
+
+			record := m.entities[e.Index()]
+			require.Equal(t, e, record.archetype.entities[record.row])
+		}
+
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentNumberKey})
+		require.NoError(t, err)
+		require.Len(t, ec, 7)
+	}
+}
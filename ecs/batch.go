@@ -0,0 +1,122 @@
+package ecs
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrDuplicateComponentType = errors.New("batch has more than one component of the same type")
+
+// CreateEntities creates n entities without components. If fewer than n entities can be created it returns
+// ErrEntityIDsExhausted and creates none of them
+func (m *Manager) CreateEntities(n int) ([]Entity, error) {
+	return m.SpawnBatch(n)
+}
+
+// SpawnBatch creates n entities that each get a copy of the given components. The archetype of the entities is
+// resolved and its storage grown once for the whole batch. If a component does not match its registered Go type, two
+// components have the same type, or fewer than n entities can be created, an error is returned and the Manager is
+// left unchanged
+func (m *Manager) SpawnBatch(n int, components ...Component) ([]Entity, error) {
+	n = max(n, 0)
+
+	// Validate the whole batch before changing anything
+	infos := make([]*componentInfo, len(components))
+	seen := make(map[string]struct{}, len(components))
+	for i, c := range components {
+		if _, ok := seen[c.Type]; ok {
+			return nil, ErrDuplicateComponentType
+		}
+		seen[c.Type] = struct{}{}
+		info, ok := m.registry.byName(c.Type)
+		if !ok {
+			continue
+		}
+		if info.relation {
+			return nil, ErrRelationTypeMismatch
+		}
+		if err := info.check(c.Data); err != nil {
+			return nil, err
+		}
+		infos[i] = info
+	}
+	if uint64(n) > m.availableIDs() {
+		return nil, ErrEntityIDsExhausted
+	}
+
+	var signature Signature
+	a := m.archetypes[0]
+	for i, c := range components {
+		if infos[i] == nil {
+			infos[i] = m.registerComponent(newComponentInfo[any](c.Type, false, TableStorage))
+		}
+		signature.Set(infos[i].ID)
+		if infos[i].set != nil {
+			infos[i].set.reserve(n)
+		} else if a.column(infos[i].ID) < 0 {
+			a = m.archetypeWith(a, infos[i].ID)
+		}
+	}
+	a.reserve(n)
+	m.entities = slices.Grow(m.entities, n)
+
+	columns := make([]int, len(components))
+	for i, info := range infos {
+		columns[i] = -1
+		if info.set == nil {
+			columns[i] = a.column(info.ID)
+		}
+	}
+
+	entities := make([]Entity, n)
+	for i := range entities {
+		// The number of available indices has been checked above
+		entity, _ := m.allocateEntity()
+		m.appendEntity(entity, a)
+		record := &m.entities[entity.Index()]
+		record.signature.assign(signature)
+		m.updateQueries(entity)
+		// The data has been checked above, so storing it cannot fail
+		for j, c := range components {
+			if columns[j] < 0 {
+				row := infos[j].set.insert(entity)
+				_ = infos[j].set.column.set(row, c.Data)
+				infos[j].set.column.markAdded(row, m.changeTick)
+				continue
+			}
+			_ = a.columns[columns[j]].set(record.row, c.Data)
+			a.columns[columns[j]].markAdded(record.row, m.changeTick)
+		}
+		entities[i] = entity
+	}
+
+	for _, entity := range entities {
+		for j, c := range components {
+			m.queueSetHooks(entity, infos[j], false, nil, c.Data)
+		}
+	}
+	m.runHooks()
+	return entities, nil
+}
+
+// DeleteEntities deletes all the given entities and their components. If any of the entities is not alive, or appears
+// more than once, an error is returned and none of them are deleted
+func (m *Manager) DeleteEntities(entities []Entity) error {
+	seen := make(map[Entity]struct{}, len(entities))
+	for _, entity := range entities {
+		if err := m.checkEntity(entity); err != nil {
+			return err
+		}
+		// Deleting an entity twice would find a stale handle the second time
+		if _, ok := seen[entity]; ok {
+			return ErrStaleEntity
+		}
+		seen[entity] = struct{}{}
+	}
+
+	for _, entity := range entities {
+		m.deleteEntity(entity)
+	}
+	m.runHooks()
+	return nil
+}
@@ -0,0 +1,183 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateEntities(t *testing.T) {
+	t.Log("Create entities - succeeds")
+	{
+		m := NewManager()
+		entities, err := m.CreateEntities(3)
+		require.NoError(t, err)
+		require.Equal(t, []Entity{0, 1, 2}, entities)
+		require.Equal(t, 3, m.EntityCount())
+	}
+
+	t.Log("Create entities reusing freed indices - succeeds")
+	{
+		m := NewManager()
+		require.NoError(t, m.DeleteEntity(m.CreateEntity()))
+		entities, err := m.CreateEntities(2)
+		require.NoError(t, err)
+		require.Equal(t, []Entity{newEntity(0, 1), 1}, entities)
+	}
+
+	t.Log("Create more entities than available - creates none")
+	{
+		m := NewManager(WithMaxEntities(3))
+		m.CreateEntity()
+		_, err := m.CreateEntities(3)
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+		require.Equal(t, 1, m.EntityCount())
+		entities, err := m.CreateEntities(2)
+		require.NoError(t, err)
+		require.Len(t, entities, 2)
+	}
+
+	t.Log("Create more entities than available with quarantined indices - creates none")
+	{
+		m := NewManager(WithMaxEntities(3), WithIDRecycler(NewQuarantineRecycler(1)))
+		entities, err := m.CreateEntities(3)
+		require.NoError(t, err)
+		require.NoError(t, m.DeleteEntities(entities))
+		_, err = m.CreateEntities(1)
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+		require.Equal(t, 3, m.freeIDs.Len())
+		m.Tick()
+		_, err = m.CreateEntities(3)
+		require.NoError(t, err)
+	}
+}
+
+func Test_SpawnBatch(t *testing.T) {
+	t.Log("Spawn batch - every entity gets a copy of the components")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+
+		entities, err := m.SpawnBatch(100,
+			Component{Type: positions.Name(), Data: testPosition{X: 1, Y: 2}},
+			Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+			Component{Type: "Sparse", Data: 42},
+		)
+		require.NoError(t, err)
+		require.Len(t, entities, 100)
+		require.Equal(t, 100, m.EntityCount())
+
+		p, err := positions.Get(entities[50])
+		require.NoError(t, err)
+		p.X = 10
+		for i, e := range entities {
+			p, err := positions.Get(e)
+			require.NoError(t, err)
+			if i == 50 {
+				require.Equal(t, testPosition{X: 10, Y: 2}, *p)
+			} else {
+				require.Equal(t, testPosition{X: 1, Y: 2}, *p)
+			}
+		}
+
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, "Sparse", positions.Name()})
+		require.NoError(t, err)
+		require.Len(t, ec, 100)
+		require.Equal(t, "Hello", ec[entities[99]][0].Data.(TestComponentString).content)
+		require.Equal(t, 42, ec[entities[99]][1].Data)
+
+		s, err := m.Signature(entities[0])
+		require.NoError(t, err)
+		require.Equal(t, 3, s.Count())
+	}
+
+	t.Log("Spawn batch into an archetype that already has entities - keeps them intact")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 1}}))
+		entities, err := m.SpawnBatch(2, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 2}})
+		require.NoError(t, err)
+
+		c, err := m.GetComponentOfEntity(e, TestComponentNumberKey)
+		require.NoError(t, err)
+		require.Equal(t, 1, c.Data.(TestComponentNumber).content)
+		c, err = m.GetComponentOfEntity(entities[1], TestComponentNumberKey)
+		require.NoError(t, err)
+		require.Equal(t, 2, c.Data.(TestComponentNumber).content)
+		require.Same(t, m.entities[e.Index()].archetype, m.entities[entities[1].Index()].archetype)
+	}
+
+	t.Log("Spawn batch with mismatched data - leaves the manager unchanged")
+	{
+		m := NewManager()
+		_, err := Register[testPosition](m)
+		require.NoError(t, err)
+		_, err = m.SpawnBatch(10,
+			Component{Type: "Unknown", Data: 1},
+			Component{Type: "ecs.testPosition", Data: testVelocity{}},
+		)
+		require.ErrorIs(t, err, ErrComponentDataMismatch)
+		require.Equal(t, 0, m.EntityCount())
+		require.Equal(t, 1, m.Registry().Len())
+		require.Len(t, m.archetypes, 1)
+	}
+
+	t.Log("Spawn batch with duplicate component types - leaves the manager unchanged")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("A", TableStorage))
+		added := 0
+		require.NoError(t, m.OnAdd("A", func(*Manager, Entity, any, any) {
+			added++
+		}))
+		_, err := m.SpawnBatch(2, Component{Type: "A", Data: 1}, Component{Type: "A", Data: 2})
+		require.ErrorIs(t, err, ErrDuplicateComponentType)
+		_, err = m.SpawnBatch(2, Component{Type: "Unknown", Data: 1}, Component{Type: "Unknown", Data: 2})
+		require.ErrorIs(t, err, ErrDuplicateComponentType)
+		require.Equal(t, 0, m.EntityCount())
+		require.Equal(t, 1, m.Registry().Len())
+		require.Zero(t, added)
+	}
+
+	t.Log("Spawn batch larger than the available IDs - leaves the manager unchanged")
+	{
+		m := NewManager(WithMaxEntities(5))
+		_, err := m.SpawnBatch(6, Component{Type: "Unknown", Data: 1})
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+		require.Equal(t, 0, m.EntityCount())
+		require.Equal(t, 0, m.Registry().Len())
+	}
+}
+
+func Test_DeleteEntities(t *testing.T) {
+	m := NewManager()
+	entities, err := m.SpawnBatch(5, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 1}})
+	require.NoError(t, err)
+
+	t.Log("Delete entities with a dead entity - deletes none")
+	{
+		require.NoError(t, m.DeleteEntity(entities[4]))
+		require.ErrorIs(t, m.DeleteEntities([]Entity{entities[0], entities[4]}), ErrStaleEntity)
+		require.ErrorIs(t, m.DeleteEntities([]Entity{entities[0], 42}), ErrEntityNotFound)
+		require.Equal(t, 4, m.EntityCount())
+	}
+
+	t.Log("Delete entities with a duplicate - deletes none")
+	{
+		require.ErrorIs(t, m.DeleteEntities([]Entity{entities[0], entities[1], entities[0]}), ErrStaleEntity)
+		require.Equal(t, 4, m.EntityCount())
+	}
+
+	t.Log("Delete entities - succeeds")
+	{
+		require.NoError(t, m.DeleteEntities([]Entity{entities[2], entities[0]}))
+		require.Equal(t, []Entity{entities[1], entities[3]}, slices.Collect(m.Entities()))
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentNumberKey})
+		require.NoError(t, err)
+		require.Len(t, ec, 2)
+	}
+}
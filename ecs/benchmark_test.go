@@ -0,0 +1,371 @@
+package ecs
+
+import (
+	"testing"
+)
+
+const benchmarkEntityCount = 100_000
+
+// newBenchmarkManager creates a manager where every entity has a number component, every second entity has a string
+// component and every fourth entity has a tag component
+func newBenchmarkManager(b *testing.B) *Manager {
+	m := NewManager()
+	for i := 0; i < benchmarkEntityCount; i++ {
+		e := m.CreateEntity()
+		if err := m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}}); err != nil {
+			b.Fatal(err)
+		}
+		if i%2 == 0 {
+			if err := m.AddComponentToEntity(e, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}}); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if i%4 == 0 {
+			if err := m.AddComponentToEntity(e, Component{Type: "Tag"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return m
+}
+
+func Benchmark_CreateEntityWithComponents(b *testing.B) {
+	m := NewManager()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		e := m.CreateEntity()
+		_ = m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}})
+		_ = m.AddComponentToEntity(e, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}})
+	}
+}
+
+// Benchmark_SpawnWave_* create and delete a wave of 1000 entities per op, so the size of the world stays the same
+func Benchmark_SpawnWave_Loop(b *testing.B) {
+	m := NewManager()
+	entities := make([]Entity, 1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range entities {
+			entities[j] = m.CreateEntity()
+			_ = m.AddComponentToEntity(entities[j], Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}})
+			_ = m.AddComponentToEntity(entities[j], Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}})
+		}
+		for _, e := range entities {
+			_ = m.DeleteEntity(e)
+		}
+	}
+}
+
+func Benchmark_SpawnWave_Batch(b *testing.B) {
+	m := NewManager()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		entities, err := m.SpawnBatch(1000,
+			Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}},
+			Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := m.DeleteEntities(entities); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_GetEntitiesWithComponents(b *testing.B) {
+	m := newBenchmarkManager(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.GetEntitiesWithComponents([]string{TestComponentNumberKey, TestComponentStringKey}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_GetComponentOfEntity(b *testing.B) {
+	m := newBenchmarkManager(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := m.GetComponentOfEntity(Entity(i%benchmarkEntityCount), TestComponentNumberKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_ComponentStoreGet(b *testing.B) {
+	m := NewManager()
+	positions, err := Register[testPosition](m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < benchmarkEntityCount; i++ {
+		if err := positions.Set(m.CreateEntity(), testPosition{X: float64(i)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, err := positions.Get(Entity(i % benchmarkEntityCount))
+		if err != nil {
+			b.Fatal(err)
+		}
+		p.Y++
+	}
+}
+
+func Benchmark_UpdateComponents(b *testing.B) {
+	m := newBenchmarkManager(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		entities, err := m.GetEntitiesWithComponents([]string{TestComponentNumberKey})
+		if err != nil {
+			b.Fatal(err)
+		}
+		for e, c := range entities {
+			number, _ := GetComponentData[TestComponentNumber](c, TestComponentNumberKey)
+			number.content++
+			_ = m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: *number})
+		}
+	}
+}
+
+func Benchmark_DeleteEntity(b *testing.B) {
+	m := newBenchmarkManager(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := m.CreateEntity()
+		_ = m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}})
+		if err := m.DeleteEntity(e); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkAddDeleteComponent(b *testing.B, storage StorageType) {
+	m := newBenchmarkManager(b)
+	if err := m.RegisterComponentType("Velocity", storage); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := Entity(i % benchmarkEntityCount)
+		if err := m.AddComponentToEntity(e, Component{Type: "Velocity", Data: TestComponentNumber{content: i}}); err != nil {
+			b.Fatal(err)
+		}
+		if err := m.DeleteComponentOfEntity(e, "Velocity"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_AddDeleteComponent_Table(b *testing.B) {
+	benchmarkAddDeleteComponent(b, TableStorage)
+}
+
+func Benchmark_AddDeleteComponent_SparseSet(b *testing.B) {
+	benchmarkAddDeleteComponent(b, SparseSetStorage)
+}
+
+func Benchmark_AddRemoveTag(b *testing.B) {
+	m := newBenchmarkManager(b)
+	if err := m.RegisterTag("Frozen", TableStorage); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := Entity(i % benchmarkEntityCount)
+		if err := m.AddTag(e, "Frozen"); err != nil {
+			b.Fatal(err)
+		}
+		if err := m.RemoveTag(e, "Frozen"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_SendReadEvents(b *testing.B) {
+	m := NewManager()
+	var reader EventReader[TestComponentNumber]
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 1000; j++ {
+			Send(m, TestComponentNumber{content: j})
+		}
+		sum := 0
+		for event := range Read(m, &reader) {
+			sum += event.content
+		}
+		if sum != 999*1000/2 {
+			b.Fatal(sum)
+		}
+		m.Tick()
+	}
+}
+
+// benchmarkQuery runs a query matching the 1% of entities that are selected, the selection being a tag in sparse set
+// storage that the matching entities can have instead of the table tag
+func benchmarkQuery(b *testing.B, register bool) {
+	m := newBenchmarkManager(b)
+	if err := m.RegisterTag("Selected", SparseSetStorage); err != nil {
+		b.Fatal(err)
+	}
+	for i := 1; i < benchmarkEntityCount; i += 100 {
+		if err := m.AddTag(Entity(i), "Selected"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	q, err := m.NewQuery().With(TestComponentNumberKey).AnyOf("Selected", "Tag").Without(TestComponentStringKey).Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if register {
+		q.Register()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if n := len(q.Execute()); n != benchmarkEntityCount/100 {
+			b.Fatal(n)
+		}
+	}
+}
+
+func Benchmark_Query_Unregistered(b *testing.B) {
+	benchmarkQuery(b, false)
+}
+
+func Benchmark_Query_Registered(b *testing.B) {
+	benchmarkQuery(b, true)
+}
+
+func benchmarkQueryAll(b *testing.B, register bool, order Order) {
+	m := newBenchmarkManager(b)
+	q, err := m.NewQuery().With(TestComponentNumberKey).Optional(TestComponentStringKey).OrderBy(order).Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if register {
+		q.Register()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for _, row := range q.All() {
+			number, err := RowData[TestComponentNumber](row, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if row.Has(1) {
+				sum += number.content
+			}
+		}
+		if sum != (benchmarkEntityCount-2)*benchmarkEntityCount/4 {
+			b.Fatal(sum)
+		}
+	}
+}
+
+func Benchmark_QueryAll_Unregistered(b *testing.B) {
+	benchmarkQueryAll(b, false, StorageOrder)
+}
+
+func Benchmark_QueryAll_Registered(b *testing.B) {
+	benchmarkQueryAll(b, true, StorageOrder)
+}
+
+func Benchmark_QueryAll_EntityOrder(b *testing.B) {
+	benchmarkQueryAll(b, false, EntityOrder)
+}
+
+func Benchmark_QueryAll_Typed(b *testing.B) {
+	m := NewManager()
+	positions, err := Register[testPosition](m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < benchmarkEntityCount; i++ {
+		if err := positions.Set(m.CreateEntity(), testPosition{X: float64(i)}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	q, err := m.NewQuery().With(positions.Name()).Build()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0.0
+		for _, row := range q.All() {
+			p, _ := RowData[testPosition](row, 0)
+			sum += p.X
+		}
+		if sum != float64((benchmarkEntityCount-1)*benchmarkEntityCount/2) {
+			b.Fatal(sum)
+		}
+	}
+}
+
+func Benchmark_Query2_Each(b *testing.B) {
+	m := NewManager()
+	positions, err := Register[testPosition](m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	velocities, err := Register[testVelocity](m)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := m.SpawnBatch(benchmarkEntityCount,
+		Component{Type: positions.Name(), Data: testPosition{}},
+		Component{Type: velocities.Name(), Data: testVelocity{X: 1, Y: 2}},
+	); err != nil {
+		b.Fatal(err)
+	}
+	q, err := NewQuery2[testPosition, testVelocity](m.NewQuery(), Mutable(positions.Name()), ReadOnly(velocities.Name()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := q.Each(func(entity Entity, position *testPosition, velocity *testVelocity) bool {
+			position.X += velocity.X * 0.1
+			position.Y += velocity.Y * 0.1
+			return true
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark_RefillQuarantine deletes and recreates a wave of 10000 entities per op, all of whose indices are still
+// quarantined when they are recreated
+func Benchmark_RefillQuarantine(b *testing.B) {
+	m := NewManager(WithIDRecycler(NewQuarantineRecycler(1)))
+	entities := make([]Entity, 10_000)
+	for j := range entities {
+		entities[j] = m.CreateEntity()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := m.DeleteEntities(entities); err != nil {
+			b.Fatal(err)
+		}
+		m.Tick()
+		for j := range entities {
+			entities[j] = m.CreateEntity()
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package ecs
+
+import (
+	"slices"
+)
+
+// queryCache holds the matches of a registered query, kept up to date as entities change. Queries with component
+// types in table storage only cache the archetypes they match, since every entity of such an archetype matches and
+// archetypes only change as new ones are created. Queries that also have types in sparse set storage cache the
+// matching entities themselves
+type queryCache struct {
+	archetypes []*archetype
+	// entities is nil unless the filter of the query has checkEntities set
+	entities *entitySet
+}
+
+// entitySet is a set of entities that can be iterated in O(len), with O(1) inserts and removals
+type entitySet struct {
+	// sparse holds the dense index + 1 of each entity index, 0 means the entity is not in the set
+	sparse   []uint32
+	entities []Entity
+}
+
+func (s *entitySet) has(entity Entity) bool {
+	index := entity.Index()
+	return int(index) < len(s.sparse) && s.sparse[index] != 0
+}
+
+func (s *entitySet) insert(entity Entity) {
+	index := entity.Index()
+	if int(index) >= len(s.sparse) {
+		s.sparse = append(s.sparse, make([]uint32, int(index)+1-len(s.sparse))...)
+	}
+	s.entities = append(s.entities, entity)
+	s.sparse[index] = uint32(len(s.entities))
+}
+
+// remove removes the entity by moving the last entity into its place, the entity must be in the set
+func (s *entitySet) remove(entity Entity) {
+	i := s.sparse[entity.Index()] - 1
+	moved := s.entities[len(s.entities)-1]
+	s.entities[i] = moved
+	s.entities = s.entities[:len(s.entities)-1]
+	s.sparse[moved.Index()] = i + 1
+	s.sparse[entity.Index()] = 0
+}
+
+// Register makes the Manager keep the matches of the query up to date as components are added and removed, so that
+// running the query in StorageOrder costs O(matches) instead of scanning all the archetypes or sparse sets of its
+// types. In EntityOrder the matches are still sorted on every run, which costs O(n log n) for n matches. Keeping a
+// query up to date costs a little on every archetype created, and for queries with types in sparse set storage on
+// every component added or removed, so only queries that run often should be registered. Registering a query twice
+// has no effect
+func (q *Query) Register() {
+	if q.cache != nil {
+		return
+	}
+	q.cache = &queryCache{archetypes: make([]*archetype, 0)}
+	if q.filter.checkEntities {
+		q.cache.entities = &entitySet{sparse: make([]uint32, 0), entities: make([]Entity, 0)}
+		q.m.eachMatch(q.filter, func(a *archetype, row int, entity Entity) bool {
+			q.cache.entities.insert(entity)
+			return true
+		})
+	} else {
+		for _, a := range q.m.archetypes {
+			if q.filter.matchesArchetype(a) {
+				q.cache.archetypes = append(q.cache.archetypes, a)
+			}
+		}
+	}
+	q.m.queries = append(q.m.queries, q)
+}
+
+// Unregister stops keeping the matches of the query up to date, the query keeps working without its cache
+func (q *Query) Unregister() {
+	if q.cache == nil {
+		return
+	}
+	q.cache = nil
+	q.m.queries = slices.DeleteFunc(q.m.queries, func(other *Query) bool { return other == q })
+}
+
+// IsRegistered returns true if the matches of the query are kept up to date by the Manager
+func (q *Query) IsRegistered() bool {
+	return q.cache != nil
+}
+
+// eachStored calls fn with every entity matching the query in StorageOrder, along with the archetype and row holding
+// its table components, until fn returns false
+func (q *Query) eachStored(fn func(a *archetype, row int, entity Entity) bool) {
+	switch {
+	case q.cache == nil:
+		q.m.eachMatch(q.filter, fn)
+	case q.cache.entities != nil:
+		for _, entity := range q.cache.entities.entities {
+			record := q.m.entities[entity.Index()]
+			if !fn(record.archetype, record.row, entity) {
+				return
+			}
+		}
+	default:
+		for _, a := range q.cache.archetypes {
+			for row, entity := range a.entities {
+				if !fn(a, row, entity) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// cacheArchetype adds a newly created archetype to the registered queries that match all of its entities
+func (m *Manager) cacheArchetype(a *archetype) {
+	for _, q := range m.queries {
+		if q.cache.entities == nil && q.filter.matchesArchetype(a) {
+			q.cache.archetypes = append(q.cache.archetypes, a)
+		}
+	}
+}
+
+// updateQueries adds the entity to or removes it from the registered queries that cache entities, according to its
+// current signature. It must be called whenever the signature of an entity changes
+func (m *Manager) updateQueries(entity Entity) {
+	for _, q := range m.queries {
+		if q.cache.entities == nil {
+			continue
+		}
+		matches := m.entities[entity.Index()].alive && q.filter.matches(m.entities[entity.Index()].signature)
+		switch cached := q.cache.entities.has(entity); {
+		case matches && !cached:
+			q.cache.entities.insert(entity)
+		case !matches && cached:
+			q.cache.entities.remove(entity)
+		}
+	}
+}
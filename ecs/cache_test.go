@@ -0,0 +1,106 @@
+package ecs
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QueryCache(t *testing.T) {
+	t.Log("Registered queries - agree with unregistered ones after random changes")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+		require.NoError(t, m.RegisterComponentType("Rare", SparseSetStorage))
+		require.NoError(t, m.RegisterTag("Frozen", TableStorage))
+		types := []string{"A", "B", "C", "Sparse", "Rare"}
+		for _, componentType := range types[:3] {
+			require.NoError(t, m.RegisterComponentType(componentType, TableStorage))
+		}
+
+		builders := []*QueryBuilder{
+			m.NewQuery(),
+			m.NewQuery().With("A"),
+			m.NewQuery().With("A", "B").Without("Frozen"),
+			m.NewQuery().With("Sparse"),
+			m.NewQuery().With("A").Without("Sparse"),
+			m.NewQuery().Without("Rare"),
+			m.NewQuery().With("C").Optional("Sparse").AnyOf("A", "Rare"),
+			m.NewQuery().AnyOf("B", "C").AnyOf("Sparse", "Frozen"),
+		}
+		cached := make([]*Query, len(builders))
+		uncached := make([]*Query, len(builders))
+		for i, b := range builders {
+			var err error
+			cached[i], err = b.Build()
+			require.NoError(t, err)
+			uncached[i], err = b.Build()
+			require.NoError(t, err)
+		}
+		// Register half of the queries before any entity exists and the other half midway
+		for _, q := range cached[:4] {
+			q.Register()
+			require.True(t, q.IsRegistered())
+		}
+
+		rng := rand.New(rand.NewPCG(1, 2))
+		entities := make([]Entity, 0)
+		commands := NewCommandBuffer(m)
+		for step := 0; step < 2000; step++ {
+			if step == 500 {
+				for _, q := range cached[4:] {
+					q.Register()
+				}
+			}
+			switch op := rng.IntN(10); {
+			case op == 0 || len(entities) == 0:
+				entities = append(entities, m.CreateEntity())
+			case op == 1:
+				spawned, err := m.SpawnBatch(rng.IntN(3), Component{Type: types[rng.IntN(len(types))], Data: step})
+				require.NoError(t, err)
+				entities = append(entities, spawned...)
+			case op == 2:
+				i := rng.IntN(len(entities))
+				require.NoError(t, m.DeleteEntity(entities[i]))
+				entities = append(entities[:i], entities[i+1:]...)
+			case op == 3:
+				require.NoError(t, m.AddTag(entities[rng.IntN(len(entities))], "Frozen"))
+			case op == 4:
+				entity, err := commands.Spawn(Component{Type: "Rare", Data: step})
+				require.NoError(t, err)
+				entities = append(entities, entity)
+				require.NoError(t, commands.Apply())
+			case op < 7:
+				entity := entities[rng.IntN(len(entities))]
+				require.NoError(t, m.AddComponentToEntity(entity, Component{Type: types[rng.IntN(len(types))], Data: step}))
+			default:
+				entity := entities[rng.IntN(len(entities))]
+				_ = m.DeleteComponentOfEntity(entity, types[rng.IntN(len(types))])
+			}
+
+			if step%50 == 0 || step >= 1990 {
+				for i := range cached {
+					require.Equal(t, uncached[i].Execute(), cached[i].Execute(), "query %d at step %d", i, step)
+				}
+			}
+		}
+	}
+
+	t.Log("Unregister query - keeps working without cache")
+	{
+		m, entities := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").AnyOf("Circle", "Box").Build()
+		require.NoError(t, err)
+		q.Register()
+		q.Register()
+		require.Len(t, m.queries, 1)
+		require.Len(t, q.Execute(), 3)
+
+		q.Unregister()
+		require.False(t, q.IsRegistered())
+		require.Empty(t, m.queries)
+		require.NoError(t, m.DeleteComponentOfEntity(entities[2], "Box"))
+		require.Len(t, q.Execute(), 2)
+	}
+}
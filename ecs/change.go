@@ -0,0 +1,140 @@
+package ecs
+
+import (
+	"reflect"
+)
+
+// ChangeTick returns the current change tick. Components are marked with the change tick at which they were added and
+// last changed, it advances every time a system begins a run with SystemTicks.Begin
+func (m *Manager) ChangeTick() uint64 {
+	return m.changeTick
+}
+
+// SystemTicks records when a system last ran, so that change detection reports the components added and changed
+// since then. A system owns one SystemTicks and calls Begin at the start of every run. Every change is reported to
+// each system exactly once, changes made by the system itself during a run are reported to it on its next run
+type SystemTicks struct {
+	lastRun uint64
+	thisRun uint64
+}
+
+// Begin starts a run of the system: changes made since the previous run up to now are the ones reported during this
+// run. The first run reports all components
+func (s *SystemTicks) Begin(m *Manager) {
+	s.lastRun = s.thisRun
+	s.thisRun = m.changeTick
+	m.changeTick++
+}
+
+// LastRun returns the change tick at which the previous run of the system began, or 0 before the second run
+func (s *SystemTicks) LastRun() uint64 {
+	return s.lastRun
+}
+
+// reported returns true if a component marked at the given change tick is reported to the current run
+func (s *SystemTicks) reported(tick uint64) bool {
+	return tick > s.lastRun && tick <= s.thisRun
+}
+
+// ChangeFilter restricts a query to the entities whose component of a type was added, or changed, since the querying
+// system last ran. Changes made through the pointers returned by ComponentStore.Get are not detected, use
+// ComponentStore.GetMut to change components in place
+type ChangeFilter struct {
+	goType        reflect.Type
+	componentType string
+	// added is true to only match components that were added, rather than added or changed
+	added bool
+}
+
+// Added returns a filter matching the entities whose component of type T, registered with Register, was added since
+// the querying system last ran
+func Added[T any]() ChangeFilter {
+	return ChangeFilter{goType: reflect.TypeFor[T](), added: true}
+}
+
+// Changed returns a filter matching the entities whose component of type T, registered with Register, was added or
+// changed since the querying system last ran
+func Changed[T any]() ChangeFilter {
+	return ChangeFilter{goType: reflect.TypeFor[T]()}
+}
+
+// AddedType returns a filter matching the entities whose component of the given type was added since the querying
+// system last ran
+func AddedType(componentType string) ChangeFilter {
+	return ChangeFilter{componentType: componentType, added: true}
+}
+
+// ChangedType returns a filter matching the entities whose component of the given type was added or changed since the
+// querying system last ran
+func ChangedType(componentType string) ChangeFilter {
+	return ChangeFilter{componentType: componentType}
+}
+
+// info returns the registry entry of the component type of the filter
+func (f ChangeFilter) info(m *Manager) (*componentInfo, bool) {
+	if f.goType != nil {
+		return m.registry.byType(f.goType)
+	}
+	return m.registry.byName(f.componentType)
+}
+
+// GetChangedEntitiesWithComponents works like GetEntitiesWithComponents, but only returns the entities that also
+// match all the filters for the run of the system
+func (m *Manager) GetChangedEntitiesWithComponents(types []string, system *SystemTicks, filters ...ChangeFilter) (map[Entity][]*Component, error) {
+	infos, err := m.lookupComponentTypes(types)
+	if err != nil {
+		return nil, err
+	}
+	required := append(make([]*componentInfo, 0, len(infos)+len(filters)), infos...)
+	changeInfos := make([]*componentInfo, len(filters))
+	for i, f := range filters {
+		info, ok := f.info(m)
+		if !ok {
+			return nil, ErrComponentTypeNotFound
+		}
+		changeInfos[i] = info
+		required = append(required, info)
+	}
+
+	result := make(map[Entity][]*Component)
+	rows := newComponentRows(infos)
+	m.eachMatch(m.newFilter(required, nil), func(a *archetype, row int, entity Entity) bool {
+		for i, f := range filters {
+			if !m.changeMatches(entity, changeInfos[i], f.added, system) {
+				return true
+			}
+		}
+		result[entity] = rows.fill(m, a, row, entity)
+		return true
+	})
+	return result, nil
+}
+
+// changeMatches returns true if the component of the entity was added, or changed unless added is true, in the run of
+// the system. The entity must have the component
+func (m *Manager) changeMatches(entity Entity, info *componentInfo, added bool, system *SystemTicks) bool {
+	c, row, _ := m.componentRow(entity, info)
+	addedTick, changedTick := c.ticks(row)
+	if added {
+		return system.reported(addedTick)
+	}
+	return system.reported(changedTick)
+}
+
+// ComponentTicks returns the change ticks at which the component of the given type was added to the entity and last
+// changed
+func (m *Manager) ComponentTicks(entity Entity, componentType string) (added uint64, changed uint64, err error) {
+	if err := m.checkEntity(entity); err != nil {
+		return 0, 0, err
+	}
+	info, ok := m.registry.byName(componentType)
+	if !ok {
+		return 0, 0, ErrComponentTypeNotFound
+	}
+	c, row, ok := m.componentRow(entity, info)
+	if !ok {
+		return 0, 0, ErrComponentNotFound
+	}
+	added, changed = c.ticks(row)
+	return added, changed, nil
+}
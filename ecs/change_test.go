@@ -0,0 +1,112 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChangeDetection(t *testing.T) {
+	t.Log("Added and changed components are reported once per system - succeeds")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		e1 := m.CreateEntity()
+		e2 := m.CreateEntity()
+		require.NoError(t, positions.Set(e1, testPosition{X: 1}))
+		require.NoError(t, positions.Set(e2, testPosition{X: 2}))
+
+		var render, network SystemTicks
+		render.Begin(m)
+		result, err := m.GetChangedEntitiesWithComponents([]string{positions.Name()}, &render, Added[testPosition]())
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		// Changes made after a run began are reported to the next run only
+		require.NoError(t, positions.Set(e1, testPosition{X: 3}))
+		result, err = m.GetChangedEntitiesWithComponents(nil, &render, Changed[testPosition]())
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Contains(t, result, e2)
+		render.Begin(m)
+		result, err = m.GetChangedEntitiesWithComponents([]string{positions.Name()}, &render, Changed[testPosition]())
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, testPosition{X: 3}, result[e1][0].Data)
+		result, err = m.GetChangedEntitiesWithComponents(nil, &render, Added[testPosition]())
+		require.NoError(t, err)
+		require.Empty(t, result)
+
+		render.Begin(m)
+		result, err = m.GetChangedEntitiesWithComponents(nil, &render, Changed[testPosition]())
+		require.NoError(t, err)
+		require.Empty(t, result)
+
+		// Each system has its own last run
+		network.Begin(m)
+		result, err = m.GetChangedEntitiesWithComponents(nil, &network, Changed[testPosition]())
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+	}
+
+	t.Log("Changes made in place through GetMut - are detected")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		e1 := m.CreateEntity()
+		e2 := m.CreateEntity()
+		require.NoError(t, positions.Set(e1, testPosition{}))
+		require.NoError(t, positions.Set(e2, testPosition{}))
+
+		var system SystemTicks
+		system.Begin(m)
+		p, err := positions.GetMut(e2)
+		require.NoError(t, err)
+		p.X = 1
+		p, err = positions.Get(e1)
+		require.NoError(t, err)
+		p.X = 1
+		system.Begin(m)
+		result, err := m.GetChangedEntitiesWithComponents(nil, &system, Changed[testPosition]())
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Contains(t, result, e2)
+	}
+
+	t.Log("Change ticks survive archetype moves and apply to untyped components - succeeds")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Velocity", SparseSetStorage))
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Vector2", Data: 1}))
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Velocity", Data: 1}))
+		var system SystemTicks
+		system.Begin(m)
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Velocity", Data: 2}))
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 100}))
+
+		added, changed, err := m.ComponentTicks(e, "Vector2")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), added)
+		require.Equal(t, uint64(1), changed)
+		added, changed, err = m.ComponentTicks(e, "Velocity")
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), added)
+		require.Equal(t, uint64(2), changed)
+
+		system.Begin(m)
+		result, err := m.GetChangedEntitiesWithComponents([]string{"Vector2"}, &system, ChangedType("Velocity"), AddedType("Health"))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		result, err = m.GetChangedEntitiesWithComponents([]string{"Vector2"}, &system, ChangedType("Vector2"))
+		require.NoError(t, err)
+		require.Empty(t, result)
+
+		_, err = m.GetChangedEntitiesWithComponents(nil, &system, ChangedType("Unknown"))
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		_, _, err = m.ComponentTicks(e, "Unknown")
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+}
@@ -0,0 +1,119 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+)
+
+// column is a contiguous array holding the data of a single component type, used by archetypes for each of their
+// types and by sparse sets. Rows are identified by their position, removing a row moves the last row into its place
+type column interface {
+	len() int
+	// reserve makes room for n more rows without reallocating
+	reserve(n int)
+	// appendZero adds a row holding the zero value of the component data
+	appendZero()
+	// appendFrom adds a row holding a copy of the given row of src, which must be a column of the same type
+	appendFrom(src column, row int)
+	// removeRow removes the row by moving the last row into its place
+	removeRow(row int)
+	// get returns the data at the given row
+	get(row int) any
+	// set stores the data at the given row, it returns ErrComponentDataMismatch if the data is not of the column type
+	set(row int, data any) error
+	// ticks returns the change ticks at which the component at the given row was added and last changed
+	ticks(row int) (added uint64, changed uint64)
+	// markAdded records that the component at the given row was added, and so changed, at the given change tick
+	markAdded(row int, tick uint64)
+	// markChanged records that the component at the given row was changed at the given change tick
+	markChanged(row int, tick uint64)
+}
+
+// typedColumn stores component data unboxed. Component types that are not registered with a Go type are stored in a
+// typedColumn[any]
+type typedColumn[T any] struct {
+	data []T
+	// added and changed hold the change ticks of each row, see Manager.ChangeTick
+	added   []uint64
+	changed []uint64
+}
+
+func newTypedColumn[T any]() column {
+	return &typedColumn[T]{
+		data:    make([]T, 0),
+		added:   make([]uint64, 0),
+		changed: make([]uint64, 0),
+	}
+}
+
+func (c *typedColumn[T]) len() int {
+	return len(c.data)
+}
+
+func (c *typedColumn[T]) reserve(n int) {
+	c.data = slices.Grow(c.data, n)
+	c.added = slices.Grow(c.added, n)
+	c.changed = slices.Grow(c.changed, n)
+}
+
+func (c *typedColumn[T]) appendZero() {
+	var zero T
+	c.data = append(c.data, zero)
+	c.added = append(c.added, 0)
+	c.changed = append(c.changed, 0)
+}
+
+func (c *typedColumn[T]) appendFrom(src column, row int) {
+	typed := src.(*typedColumn[T])
+	c.data = append(c.data, typed.data[row])
+	c.added = append(c.added, typed.added[row])
+	c.changed = append(c.changed, typed.changed[row])
+}
+
+func (c *typedColumn[T]) removeRow(row int) {
+	last := len(c.data) - 1
+	c.data[row] = c.data[last]
+	// Clear the vacated slot so the column does not keep the component data alive
+	var zero T
+	c.data[last] = zero
+	c.data = c.data[:last]
+	c.added[row] = c.added[last]
+	c.added = c.added[:last]
+	c.changed[row] = c.changed[last]
+	c.changed = c.changed[:last]
+}
+
+func (c *typedColumn[T]) get(row int) any {
+	return c.data[row]
+}
+
+func (c *typedColumn[T]) set(row int, data any) error {
+	value, err := castData[T](data)
+	if err != nil {
+		return err
+	}
+	c.data[row] = value
+	return nil
+}
+
+func (c *typedColumn[T]) ticks(row int) (uint64, uint64) {
+	return c.added[row], c.changed[row]
+}
+
+func (c *typedColumn[T]) markAdded(row int, tick uint64) {
+	c.added[row] = tick
+	c.changed[row] = tick
+}
+
+func (c *typedColumn[T]) markChanged(row int, tick uint64) {
+	c.changed[row] = tick
+}
+
+// castData converts the data of a Component to T. nil data is only accepted when T is an interface type
+func castData[T any](data any) (T, error) {
+	value, ok := data.(T)
+	if !ok && (data != nil || reflect.TypeFor[T]().Kind() != reflect.Interface) {
+		return value, ErrComponentDataMismatch
+	}
+	return value, nil
+}
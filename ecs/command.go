@@ -0,0 +1,262 @@
+package ecs
+
+import (
+	"errors"
+	"slices"
+)
+
+// CommandBuffer records structural changes, such as spawns, despawns and component adds and removes, so they can be
+// made while iterating over query results and applied together at a sync point. Entities spawned by the buffer get
+// their handle when the spawn is recorded, so later commands can refer to them before they exist
+type CommandBuffer struct {
+	m        *Manager
+	commands []command
+	// reserved holds the entities whose spawn has been recorded but not applied yet
+	reserved []Entity
+}
+
+// command is a recorded change. check returns the error apply would return if the changes planned by the commands
+// recorded before it were applied, and adds the changes of the command to the plan
+type command struct {
+	check func(p *commandPlan) error
+	apply func() error
+}
+
+// NewCommandBuffer returns an empty command buffer for the manager
+func NewCommandBuffer(m *Manager) *CommandBuffer {
+	return &CommandBuffer{
+		m:        m,
+		commands: make([]command, 0),
+		reserved: make([]Entity, 0),
+	}
+}
+
+// Len returns the number of commands waiting to be applied
+func (b *CommandBuffer) Len() int {
+	return len(b.commands)
+}
+
+// Spawn records the creation of an entity with the given components and returns its handle. The index of the entity
+// is reserved right away, the entity is alive once the buffer is applied. It returns ErrEntityIDsExhausted if no
+// index is available
+func (b *CommandBuffer) Spawn(components ...Component) (Entity, error) {
+	entity, ok := b.m.reserveEntity()
+	if !ok {
+		return 0, ErrEntityIDsExhausted
+	}
+	b.reserved = append(b.reserved, entity)
+	components = slices.Clone(components)
+	b.commands = append(b.commands, command{
+		check: func(p *commandPlan) error {
+			p.alive[entity] = true
+			for _, c := range components {
+				if err := p.addComponent(entity, c); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		apply: func() error {
+			b.m.entities[entity.Index()].alive = true
+			b.m.alive++
+			b.m.appendEntity(entity, b.m.archetypes[0])
+			b.m.updateQueries(entity)
+
+			errs := make([]error, 0)
+			for _, c := range components {
+				if err := b.m.AddComponentToEntity(entity, c); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		},
+	})
+	return entity, nil
+}
+
+// Despawn records the deletion of the entity, see Manager.DeleteEntity
+func (b *CommandBuffer) Despawn(entity Entity) {
+	b.commands = append(b.commands, command{
+		check: func(p *commandPlan) error {
+			if err := p.checkEntity(entity); err != nil {
+				return err
+			}
+			p.alive[entity] = false
+			return nil
+		},
+		apply: func() error {
+			return b.m.DeleteEntity(entity)
+		},
+	})
+}
+
+// AddComponent records adding the component to the entity, see Manager.AddComponentToEntity
+func (b *CommandBuffer) AddComponent(entity Entity, component Component) {
+	b.commands = append(b.commands, command{
+		check: func(p *commandPlan) error {
+			if err := p.checkEntity(entity); err != nil {
+				return err
+			}
+			return p.addComponent(entity, component)
+		},
+		apply: func() error {
+			return b.m.AddComponentToEntity(entity, component)
+		},
+	})
+}
+
+// RemoveComponent records removing the component of the given type from the entity, see
+// Manager.DeleteComponentOfEntity
+func (b *CommandBuffer) RemoveComponent(entity Entity, componentType string) {
+	b.commands = append(b.commands, command{
+		check: func(p *commandPlan) error {
+			if err := p.checkEntity(entity); err != nil {
+				return err
+			}
+			return p.removeComponent(entity, componentType)
+		},
+		apply: func() error {
+			// Despawning the last target of a relation of the entity removes the relation, which the plan does not
+			// follow, so the relation is allowed to be gone already
+			info, ok := b.m.registry.byName(componentType)
+			if ok && info.relation && !b.m.entities[entity.Index()].signature.Has(info.ID) {
+				return nil
+			}
+			return b.m.DeleteComponentOfEntity(entity, componentType)
+		},
+	})
+}
+
+// Defer records a call to fn, for changes that have no command of their own such as SetParent or AddRelation. Unlike
+// the other commands, fn is not checked before the buffer is applied, see Apply
+func (b *CommandBuffer) Defer(fn func(m *Manager) error) {
+	b.commands = append(b.commands, command{
+		check: func(p *commandPlan) error {
+			return nil
+		},
+		apply: func() error {
+			return fn(b.m)
+		},
+	})
+}
+
+// Apply applies the commands in the order they were recorded and empties the buffer. Every command is checked against
+// the state the commands recorded before it leave the Manager in before anything is applied: if any of them would
+// fail, for example because its entity has been deleted in the meantime, nothing is applied, the buffer is left as it
+// is and the errors of all failing commands are returned joined together. No other code runs between the commands:
+// the hooks they trigger run once all commands have been applied.
+//
+// Functions recorded with Defer are the exception, they can only be run. The changes they make are not known when the
+// commands are checked, and the errors they return are returned once all commands have been applied, without undoing
+// any of them
+func (b *CommandBuffer) Apply() error {
+	plan := newCommandPlan(b.m)
+	errs := make([]error, 0)
+	for _, c := range b.commands {
+		if err := c.check(plan); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	commands := b.commands
+	b.commands = make([]command, 0)
+	// Every recorded spawn is applied below
+	b.reserved = b.reserved[:0]
+
+	b.m.deferHooks(func() {
+		for _, c := range commands {
+			if err := c.apply(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Discard drops the commands without applying them, and releases the indices reserved for the entities the buffer
+// would have spawned, whose handles become stale
+func (b *CommandBuffer) Discard() {
+	for _, entity := range b.reserved {
+		b.m.releaseIndex(entity.Index())
+	}
+	b.reserved = b.reserved[:0]
+	b.commands = make([]command, 0)
+}
+
+// plannedComponent identifies the component of a type on an entity
+type plannedComponent struct {
+	entity        Entity
+	componentType string
+}
+
+// commandPlan follows the changes the checked commands of a CommandBuffer would make to the Manager
+type commandPlan struct {
+	m *Manager
+	// alive holds the entities spawned or despawned by the commands
+	alive map[Entity]bool
+	// components holds whether the entities have the components added or removed by the commands
+	components map[plannedComponent]bool
+	// types holds the component types that are not known yet and are registered by the commands
+	types map[string]bool
+}
+
+func newCommandPlan(m *Manager) *commandPlan {
+	return &commandPlan{
+		m:          m,
+		alive:      make(map[Entity]bool),
+		components: make(map[plannedComponent]bool),
+		types:      make(map[string]bool),
+	}
+}
+
+// checkEntity returns the error Manager.checkEntity would return once the commands are applied
+func (p *commandPlan) checkEntity(entity Entity) error {
+	alive, ok := p.alive[entity]
+	switch {
+	case !ok:
+		return p.m.checkEntity(entity)
+	case !alive:
+		// Deleting an entity bumps the generation of its index
+		return ErrStaleEntity
+	}
+	return nil
+}
+
+// addComponent checks adding the component to a live entity like Manager.AddComponentToEntity
+func (p *commandPlan) addComponent(entity Entity, c Component) error {
+	if info, ok := p.m.registry.byName(c.Type); ok {
+		if info.relation {
+			return ErrRelationTypeMismatch
+		}
+		if err := info.check(c.Data); err != nil {
+			return err
+		}
+	} else {
+		// Unknown types are registered accepting data of any Go type
+		p.types[c.Type] = true
+	}
+	p.components[plannedComponent{entity: entity, componentType: c.Type}] = true
+	return nil
+}
+
+// removeComponent checks removing the component of the given type from a live entity like
+// Manager.DeleteComponentOfEntity
+func (p *commandPlan) removeComponent(entity Entity, componentType string) error {
+	key := plannedComponent{entity: entity, componentType: componentType}
+	info, known := p.m.registry.byName(componentType)
+	if !known && !p.types[componentType] {
+		return ErrComponentTypeNotFound
+	}
+	has, planned := p.components[key]
+	if !planned {
+		has = known && p.m.entities[entity.Index()].signature.Has(info.ID)
+	}
+	if !has {
+		return ErrComponentNotFound
+	}
+	p.components[key] = false
+	return nil
+}
@@ -0,0 +1,167 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CommandBuffer(t *testing.T) {
+	t.Log("Record changes while iterating and apply them - succeeds")
+	{
+		m := NewManager()
+		entities, err := m.SpawnBatch(10, Component{Type: "Health", Data: 0})
+		require.NoError(t, err)
+		for i, e := range entities[5:] {
+			require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: i + 1}))
+		}
+
+		commands := NewCommandBuffer(m)
+		result, err := m.GetEntitiesWithComponents([]string{"Health"})
+		require.NoError(t, err)
+		for e, c := range result {
+			if c[0].Data.(int) == 0 {
+				commands.Despawn(e)
+				continue
+			}
+			commands.AddComponent(e, Component{Type: "Alive", Data: true})
+		}
+		require.Equal(t, 10, commands.Len())
+		require.Equal(t, 10, m.EntityCount())
+
+		require.NoError(t, commands.Apply())
+		require.Equal(t, 0, commands.Len())
+		require.Equal(t, 5, m.EntityCount())
+		result, err = m.GetEntitiesWithComponents([]string{"Health", "Alive"})
+		require.NoError(t, err)
+		require.Len(t, result, 5)
+	}
+
+	t.Log("Refer to entities that are not spawned yet - succeeds")
+	{
+		m := NewManager()
+		commands := NewCommandBuffer(m)
+		ship, err := commands.Spawn(Component{Type: "Name", Data: "ship"})
+		require.NoError(t, err)
+		turret, err := commands.Spawn()
+		require.NoError(t, err)
+		require.NotEqual(t, ship, turret)
+		require.False(t, m.IsAlive(ship))
+		require.Equal(t, 0, m.EntityCount())
+		require.Empty(t, slices.Collect(m.Entities()))
+
+		commands.AddComponent(turret, Component{Type: "Name", Data: "turret"})
+		commands.Defer(func(m *Manager) error {
+			return m.SetParent(turret, ship)
+		})
+		require.NoError(t, commands.Apply())
+
+		require.True(t, m.IsAlive(ship))
+		require.Equal(t, []Entity{turret}, slices.Collect(m.Children(ship)))
+		c, err := m.GetComponentOfEntity(turret, "Name")
+		require.NoError(t, err)
+		require.Equal(t, "turret", c.Data)
+		// Reserved indices are not handed out again
+		require.Equal(t, turret.Index()+1, m.CreateEntity().Index())
+	}
+
+	t.Log("Apply commands that fail - applies nothing and returns all errors")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		commands := NewCommandBuffer(m)
+		commands.Despawn(e)
+		commands.AddComponent(e, Component{Type: "Health", Data: 1})
+		spawned, err := commands.Spawn(Component{Type: "Health", Data: 1})
+		require.NoError(t, err)
+		commands.RemoveComponent(spawned, "Unknown")
+
+		err = commands.Apply()
+		require.ErrorIs(t, err, ErrStaleEntity)
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		require.True(t, m.IsAlive(e))
+		require.False(t, m.IsAlive(spawned))
+		require.Equal(t, 4, commands.Len())
+
+		commands.Discard()
+		require.Equal(t, 0, commands.Len())
+		require.Equal(t, 1, m.EntityCount())
+	}
+
+	t.Log("Apply commands that depend on each other - succeeds")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		e := m.CreateEntity()
+		commands := NewCommandBuffer(m)
+		commands.AddComponent(e, Component{Type: "Health", Data: 1})
+		commands.RemoveComponent(e, "Health")
+		spawned, err := commands.Spawn(Component{Type: "Name", Data: "spawned"})
+		require.NoError(t, err)
+		commands.RemoveComponent(spawned, "Name")
+		commands.Despawn(e)
+
+		require.NoError(t, commands.Apply())
+		require.False(t, m.IsAlive(e))
+		require.True(t, m.IsAlive(spawned))
+		_, err = m.GetComponentOfEntity(spawned, "Name")
+		require.ErrorIs(t, err, ErrComponentNotFound)
+	}
+
+	t.Log("Apply commands with mismatching data or removing missing components - applies nothing")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		e := m.CreateEntity()
+		commands := NewCommandBuffer(m)
+		commands.AddComponent(e, Component{Type: "Health", Data: 1})
+		commands.AddComponent(e, Component{Type: positions.Name(), Data: "not a position"})
+		commands.RemoveComponent(e, positions.Name())
+
+		err = commands.Apply()
+		require.ErrorIs(t, err, ErrComponentDataMismatch)
+		require.ErrorIs(t, err, ErrComponentNotFound)
+		_, err = m.GetComponentOfEntity(e, "Health")
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+
+	t.Log("Discard commands - releases reserved entities")
+	{
+		m := NewManager(WithMaxEntities(2))
+		commands := NewCommandBuffer(m)
+		first, err := commands.Spawn()
+		require.NoError(t, err)
+		_, err = commands.Spawn()
+		require.NoError(t, err)
+		_, err = commands.Spawn()
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+		_, err = m.TryCreateEntity()
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+
+		commands.Discard()
+		require.NoError(t, commands.Apply())
+		require.Equal(t, 0, m.EntityCount())
+		e := m.CreateEntity()
+		require.Equal(t, first.Index(), e.Index())
+		require.ErrorIs(t, m.DeleteEntity(first), ErrStaleEntity)
+	}
+
+	t.Log("Apply commands - runs hooks once all commands are applied")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		counts := make([]int, 0)
+		require.NoError(t, m.OnAdd("Health", func(m *Manager, _ Entity, _ any, _ any) {
+			counts = append(counts, m.EntityCount())
+		}))
+		commands := NewCommandBuffer(m)
+		for range 3 {
+			_, err := commands.Spawn(Component{Type: "Health", Data: 1})
+			require.NoError(t, err)
+		}
+		require.NoError(t, commands.Apply())
+		require.Equal(t, []int{3, 3, 3}, counts)
+	}
+}
@@ -14,6 +14,12 @@ func Vector2(x float64, y float64) ecs.Component {
 	}
 }
 
+// RegisterVector2 registers Vector2 components stored as r2.Vec, so that systems change them in place. It must be
+// called before any Vector2 component is added
+func RegisterVector2(m *ecs.Manager) (*ecs.ComponentStore[r2.Vec], error) {
+	return ecs.Register[r2.Vec](m, ecs.WithName("Vector2"))
+}
+
 func Vector3(x float64, y float64, z float64) ecs.Component {
 	return ecs.Component{
 		Type: "Vector3",
@@ -2,15 +2,18 @@ package ecs
 
 import (
 	"errors"
+	"iter"
+	"math"
+	"reflect"
 )
 
 var ErrComponentTypeNotFound = errors.New("manager does not have components of this type")
 var ErrComponentNotFound = errors.New("component not found")
 var ErrEntityNotFound = errors.New("entity not found")
 var ErrComponentDataMismatch = errors.New("component data type mismatch")
-
-// Entity acts as a container of components, it is just an ID
-type Entity uint32
+var ErrStaleEntity = errors.New("entity handle is stale, the entity has been deleted")
+var ErrEntityIDsExhausted = errors.New("all entity IDs are in use")
+var ErrStorageTypeMismatch = errors.New("component type is already registered with a different storage type")
 
 type Component struct {
 	Type string
@@ -26,128 +29,545 @@ func GetDataAsType[T any](c *Component) (T, error) {
 	return data, nil
 }
 
+// StorageType selects how the components of a type are stored
+type StorageType int
+
+const (
+	// TableStorage stores components in the archetype tables, which is the fastest to iterate but moves all the
+	// components of an entity whenever a component is added or removed. Types that are not registered use TableStorage
+	TableStorage StorageType = iota
+	// SparseSetStorage stores components in a sparse set per type, adding and removing them is O(1) and does not move
+	// the other components of the entity, which suits components that are added and removed often
+	SparseSetStorage
+)
+
 // Manager is a generic type that manages components
 type Manager struct {
-	// components mapped by type -> [entity -> component]
-	components map[string]map[Entity]*Component
-	nextID     Entity
-	// freeIDs is a list of IDs that have been deleted and can be reused
-	freeIDs []Entity
+	// archetypes holds every archetype in order of creation, the first one holds the entities without components
+	archetypes []*archetype
+	// archetypeIndex maps the key of a set of component types to its archetype
+	archetypeIndex map[string]*archetype
+	// componentIndex holds the archetypes that contain each component type, indexed by ComponentID
+	componentIndex [][]*archetype
+	registry       *ComponentRegistry
+	// sparseTypes holds the IDs of all component types in sparse set storage
+	sparseTypes Signature
+	// nextID is the next index that has never been handed out, maxEntities bounds the indices that can be handed out
+	nextID      uint64
+	maxEntities uint64
+	// entities holds the record of every entity index that has been handed out
+	entities []entityRecord
+	// alive is the number of entities that have been created and not deleted
+	alive int
+	// freeIDs holds the indices that have been deleted and decides when they are reused
+	freeIDs IDRecycler
+	tick    uint64
+	// relationSources maps each target of a relation to the sources related to it, by relation type
+	relationSources map[Entity]map[ComponentID][]Entity
+	// names maps the name of every named entity to the entity
+	names map[string]Entity
+	// resources maps the Go type of every world resource to a pointer to it
+	resources map[reflect.Type]any
+	// hookQueue holds the hooks waiting to run, runningHooks is true while they run
+	hookQueue    []hookCall
+	runningHooks bool
+	// changeTick is the tick components are marked as added or changed at, it advances every time a system begins a run
+	changeTick uint64
+	// removalLogs holds the logs of the tracked component types and the log of deleted entities, which is nil until
+	// despawns are tracked
+	removalLogs []*removalLog
+	despawns    *removalLog
+	// events maps the Go type of every event type that has been sent to its eventQueue
+	events map[reflect.Type]eventBuffer
+	// queries holds the registered queries, whose matches are kept up to date
+	queries []*Query
+}
+
+// ManagerOption configures a Manager created with NewManager
+type ManagerOption func(*Manager)
+
+// WithIDRecycler sets the policy for reusing the indices of deleted entities, the default is NewFIFORecycler
+func WithIDRecycler(recycler IDRecycler) ManagerOption {
+	return func(m *Manager) {
+		m.freeIDs = recycler
+	}
 }
 
-func NewManager() *Manager {
-	return &Manager{
-		components: make(map[string]map[Entity]*Component),
-		nextID:     0,
-		freeIDs:    make([]Entity, 0),
+// WithMaxEntities bounds the number of entity indices the manager hands out, the default is the full 32 bit index space
+func WithMaxEntities(count uint32) ManagerOption {
+	return func(m *Manager) {
+		m.maxEntities = uint64(count)
+	}
+}
+
+func NewManager(options ...ManagerOption) *Manager {
+	m := &Manager{
+		archetypes:      make([]*archetype, 0),
+		archetypeIndex:  make(map[string]*archetype),
+		componentIndex:  make([][]*archetype, 0),
+		registry:        newComponentRegistry(),
+		nextID:          0,
+		maxEntities:     1 << entityIndexBits,
+		entities:        make([]entityRecord, 0),
+		freeIDs:         NewFIFORecycler(),
+		relationSources: make(map[Entity]map[ComponentID][]Entity),
+		names:           make(map[string]Entity),
+		resources:       make(map[reflect.Type]any),
+		changeTick:      1,
+		events:          make(map[reflect.Type]eventBuffer),
+		queries:         make([]*Query, 0),
+	}
+	for _, option := range options {
+		option(m)
+	}
+	m.getArchetype([]ComponentID{})
+	return m
+}
+
+// Tick advances the world tick, which is used by the IDRecycler to quarantine freed indices, and drops the removals,
+// deletions and events from before the previous tick
+func (m *Manager) Tick() {
+	m.tick++
+	for _, log := range m.removalLogs {
+		log.prune(m.tick)
+	}
+	for _, buffer := range m.events {
+		buffer.update()
 	}
 }
 
+// CurrentTick returns the world tick
+func (m *Manager) CurrentTick() uint64 {
+	return m.tick
+}
+
 /** Entity management */
 
-// CreateEntity increments the entity ID counter and returns the next ID in the sequence
+// CreateEntity returns a handle for a recycled index if one is available, otherwise it increments the entity ID counter
+// and returns a handle for the next index in the sequence. It panics with ErrEntityIDsExhausted when no index is left,
+// use TryCreateEntity to handle that case
 func (m *Manager) CreateEntity() Entity {
-	if len(m.freeIDs) > 0 {
-		id := m.freeIDs[0]
-		m.freeIDs = m.freeIDs[1:]
-		return id
+	entity, err := m.TryCreateEntity()
+	if err != nil {
+		panic(err)
 	}
-	m.nextID++
-	return m.nextID - 1
+	return entity
 }
 
-// DeleteEntity deletes the entity and all its components, and stores the ID in the freeIDs list
-func (m *Manager) DeleteEntity(entity Entity) error {
-	deleted := false
-	for _, components := range m.components {
-		if _, ok := components[entity]; !ok {
-			continue
+// TryCreateEntity works like CreateEntity, but returns ErrEntityIDsExhausted when every index is in use or quarantined
+func (m *Manager) TryCreateEntity() (Entity, error) {
+	entity, ok := m.allocateEntity()
+	if !ok {
+		return 0, ErrEntityIDsExhausted
+	}
+	m.appendEntity(entity, m.archetypes[0])
+	m.updateQueries(entity)
+	return entity, nil
+}
+
+// availableIDs returns the number of entities that can be created right now
+func (m *Manager) availableIDs() uint64 {
+	return uint64(m.freeIDs.Available(m.tick)) + m.maxEntities - m.nextID
+}
+
+// allocateEntity marks the next recycled or new index as alive and returns its handle, or false if no index is
+// available. The caller must add the entity to an archetype
+func (m *Manager) allocateEntity() (Entity, bool) {
+	entity, ok := m.reserveEntity()
+	if !ok {
+		return 0, false
+	}
+	m.entities[entity.Index()].alive = true
+	m.alive++
+	return entity, true
+}
+
+// reserveEntity takes the next recycled or new index and returns its handle without making the entity alive, or false
+// if no index is available. The index stays reserved until the entity is made alive or released
+func (m *Manager) reserveEntity() (Entity, bool) {
+	index, ok := m.freeIDs.Next(m.tick)
+	if !ok {
+		if m.nextID >= m.maxEntities {
+			return 0, false
 		}
-		delete(components, entity)
-		deleted = true
+		index = uint32(m.nextID)
+		m.nextID++
+		m.entities = append(m.entities, entityRecord{})
 	}
+	return newEntity(index, m.entities[index].generation), true
+}
+
+// releaseIndex bumps the generation of an index that is no longer used and hands it to the IDRecycler. An index whose
+// generation cannot be bumped any further is retired instead
+func (m *Manager) releaseIndex(index uint32) {
+	record := &m.entities[index]
+	if record.generation == math.MaxUint32 {
+		return
+	}
+	record.generation++
+	m.freeIDs.Free(index, m.tick)
+}
 
-	if !deleted {
+// checkEntity returns ErrEntityNotFound when the entity was never created or its index is free,
+// and ErrStaleEntity when the generation of the handle does not match the current generation of its index
+func (m *Manager) checkEntity(entity Entity) error {
+	index := entity.Index()
+	if int(index) >= len(m.entities) {
+		return ErrEntityNotFound
+	}
+	record := m.entities[index]
+	if record.generation != entity.Generation() {
+		return ErrStaleEntity
+	}
+	if !record.alive {
 		return ErrEntityNotFound
 	}
-	m.freeIDs = append(m.freeIDs, entity)
 	return nil
 }
 
+// IsAlive returns true if the entity has been created and not deleted since
+func (m *Manager) IsAlive(entity Entity) bool {
+	return m.checkEntity(entity) == nil
+}
+
+// EntityCount returns the number of live entities
+func (m *Manager) EntityCount() int {
+	return m.alive
+}
+
+// Entities returns an iterator over all live entities in order of their index
+func (m *Manager) Entities() iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		for index, record := range m.entities {
+			if !record.alive {
+				continue
+			}
+			if !yield(newEntity(uint32(index), record.generation)) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteEntity deletes the entity and all its components, bumps the generation of its index and hands the index to the
+// IDRecycler. An index whose generation cannot be bumped any further is retired instead, so that no handle is ever
+// handed out twice. The name of the entity is released, and its children lose their parent, use DeleteEntityRecursive
+// to delete them as well
+func (m *Manager) DeleteEntity(entity Entity) error {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	m.deleteEntity(entity)
+	m.runHooks()
+	return nil
+}
+
+// deleteEntity deletes a live entity and queues the OnRemove hooks of its components, see DeleteEntity
+func (m *Manager) deleteEntity(entity Entity) {
+	m.deleteRelations(entity)
+	record := &m.entities[entity.Index()]
+	for id := range record.signature.IDs() {
+		info := m.registry.components[id]
+		m.recordRemoval(entity, info)
+		if info.hooks != nil && len(info.hooks.onRemove) > 0 {
+			c, row, _ := m.componentRow(entity, info)
+			m.queueHooks(info.hooks.onRemove, entity, componentData(info, c, row), nil)
+		}
+	}
+	if m.despawns != nil {
+		m.despawns.record(entity, m.changeTick, m.tick)
+	}
+	m.removeEntity(entity)
+	for id := range record.signature.IDs() {
+		if set := m.registry.components[id].set; set != nil {
+			set.remove(entity)
+		}
+	}
+	record.signature.reset()
+	m.detachEntity(entity)
+	m.releaseName(entity)
+	record.alive = false
+	m.alive--
+	m.updateQueries(entity)
+	m.releaseIndex(entity.Index())
+}
+
 /** Component management **/
 
-// AddComponentToEntity adds a component to an entity
+// RegisterComponentType selects the storage used for components of the given type, which accept data of any Go type.
+// It returns ErrStorageTypeMismatch if the type is already known with a different storage type
+func (m *Manager) RegisterComponentType(componentType string, storage StorageType) error {
+	if info, ok := m.registry.byName(componentType); ok {
+		if info.Storage != storage {
+			return ErrStorageTypeMismatch
+		}
+		return nil
+	}
+	m.registerComponent(newComponentInfo[any](componentType, false, storage))
+	return nil
+}
+
+// Registry returns the registry of the component types known to the manager
+func (m *Manager) Registry() *ComponentRegistry {
+	return m.registry
+}
+
+// registerComponent assigns an ID to the component type and creates its storage
+func (m *Manager) registerComponent(info *componentInfo) *componentInfo {
+	m.registry.add(info)
+	if info.Storage == SparseSetStorage {
+		info.set = newSparseSet(info.newColumn())
+		m.sparseTypes.Set(info.ID)
+	}
+	m.componentIndex = append(m.componentIndex, make([]*archetype, 0))
+	return info
+}
+
+// componentRow returns the column and row holding the component of the entity, or false if the entity does not have it
+func (m *Manager) componentRow(entity Entity, info *componentInfo) (column, int, bool) {
+	if info.set != nil {
+		row := info.set.dense(entity)
+		return info.set.column, row, row >= 0
+	}
+	record := m.entities[entity.Index()]
+	i := record.archetype.column(info.ID)
+	if i < 0 {
+		return nil, 0, false
+	}
+	return record.archetype.columns[i], record.row, true
+}
+
+// insertComponent returns the column and row holding the component of the entity, if the entity does not have the
+// component yet it is added with the zero value, moving the entity to a new archetype for types in table storage, and
+// marked as added at the current change tick
+func (m *Manager) insertComponent(entity Entity, info *componentInfo) (column, int) {
+	if c, row, ok := m.componentRow(entity, info); ok {
+		return c, row
+	}
+
+	m.entities[entity.Index()].signature.Set(info.ID)
+	if info.set != nil {
+		row := info.set.insert(entity)
+		info.set.column.markAdded(row, m.changeTick)
+		m.updateQueries(entity)
+		return info.set.column, row
+	}
+
+	target := m.archetypeWith(m.entities[entity.Index()].archetype, info.ID)
+	m.moveEntity(entity, target)
+	c, row := target.columns[target.column(info.ID)], m.entities[entity.Index()].row
+	c.markAdded(row, m.changeTick)
+	m.updateQueries(entity)
+	return c, row
+}
+
+// removeComponent removes the component of the entity and queues the OnRemove hooks of the type, it returns false if
+// the entity does not have the component
+func (m *Manager) removeComponent(entity Entity, info *componentInfo) bool {
+	record := &m.entities[entity.Index()]
+	if !record.signature.Has(info.ID) {
+		return false
+	}
+	m.recordRemoval(entity, info)
+	if info.hooks != nil && len(info.hooks.onRemove) > 0 {
+		c, row, _ := m.componentRow(entity, info)
+		m.queueHooks(info.hooks.onRemove, entity, componentData(info, c, row), nil)
+	}
+
+	record.signature.Clear(info.ID)
+	if info.set != nil {
+		info.set.remove(entity)
+	} else {
+		m.moveEntity(entity, m.archetypeWithout(record.archetype, info.ID))
+	}
+	m.updateQueries(entity)
+	return true
+}
+
+// Signature returns the IDs of the component types of the entity
+func (m *Manager) Signature(entity Entity) (Signature, error) {
+	if err := m.checkEntity(entity); err != nil {
+		return Signature{}, err
+	}
+	return m.entities[entity.Index()].signature.Clone(), nil
+}
+
+// AddComponentToEntity adds a component to an entity, replacing the component of the same type if the entity has one.
+// Types that are not known yet are registered with TableStorage and accept data of any Go type. For types registered
+// with Register the data must be of the registered Go type, otherwise ErrComponentDataMismatch is returned
 func (m *Manager) AddComponentToEntity(entity Entity, component Component) error {
-	if _, ok := m.components[component.Type]; !ok {
-		m.components[component.Type] = make(map[Entity]*Component)
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	info, ok := m.registry.byName(component.Type)
+	if !ok {
+		info = m.registerComponent(newComponentInfo[any](component.Type, false, TableStorage))
+	}
+	if info.relation {
+		return ErrRelationTypeMismatch
+	}
+	if err := info.check(component.Data); err != nil {
+		return err
 	}
-	m.components[component.Type][entity] = &component
+
+	m.setComponent(entity, info, component.Data)
+	m.runHooks()
 	return nil
 }
 
-// GetComponentOfEntity returns the component of the given type on the given entity
+// GetComponentOfEntity returns the component of the given type on the given entity. The returned component is a copy,
+// changes to it are only stored by passing it to AddComponentToEntity
 func (m *Manager) GetComponentOfEntity(entity Entity, componentType string) (*Component, error) {
-	if _, ok := m.components[componentType]; !ok {
+	if err := m.checkEntity(entity); err != nil {
+		return nil, err
+	}
+	info, ok := m.registry.byName(componentType)
+	if !ok {
 		return nil, ErrComponentTypeNotFound
 	}
-	c, ok := m.components[componentType][entity]
+	c, row, ok := m.componentRow(entity, info)
 	if !ok {
 		return nil, ErrComponentNotFound
 	}
-	return c, nil
+	return &Component{Type: componentType, Data: componentData(info, c, row)}, nil
 }
 
 func (m *Manager) DeleteComponentOfEntity(entity Entity, componentType string) error {
-	if _, ok := m.components[componentType]; !ok {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	info, ok := m.registry.byName(componentType)
+	if !ok {
 		return ErrComponentTypeNotFound
 	}
-	if _, ok := m.components[componentType][entity]; !ok {
+	if info.relation && m.entities[entity.Index()].signature.Has(info.ID) {
+		m.removeRelations(entity, info)
+		return nil
+	}
+	if !m.removeComponent(entity, info) {
 		return ErrComponentNotFound
 	}
-	delete(m.components[componentType], entity)
+	m.runHooks()
 	return nil
 }
 
-// GetEntitiesWithComponents returns entities and components where the entity has all types of components
+// GetEntitiesWithComponents returns entities and components where the entity has all types of components, the
+// components of each entity are in the same order as the requested types. The components are copies, changes to them
+// are only stored by passing them to AddComponentToEntity. The matches are searched on every call, and being a map the
+// result has no order, code that runs often or depends on the order of the entities should iterate over a Query
+// instead. No types match no entities
 func (m *Manager) GetEntitiesWithComponents(types []string) (map[Entity][]*Component, error) {
+	if len(types) == 0 {
+		return make(map[Entity][]*Component), nil
+	}
+	return m.GetEntitiesWithComponentsFiltered(types, nil, nil)
+}
+
+// GetEntitiesWithComponentsFiltered works like GetEntitiesWithComponents, but only returns the entities that also have
+// all the types in with and none of the types in without, without returning their components. The types in with and
+// without are usually tags. Unknown types in without are ignored
+func (m *Manager) GetEntitiesWithComponentsFiltered(types []string, with []string, without []string) (map[Entity][]*Component, error) {
+	infos, err := m.lookupComponentTypes(types)
+	if err != nil {
+		return nil, err
+	}
+	withInfos, err := m.lookupComponentTypes(with)
+	if err != nil {
+		return nil, err
+	}
+	withoutInfos := make([]*componentInfo, 0, len(without))
+	for _, t := range without {
+		if info, ok := m.registry.byName(t); ok {
+			withoutInfos = append(withoutInfos, info)
+		}
+	}
+
 	result := make(map[Entity][]*Component)
+	rows := newComponentRows(infos)
+	m.eachMatch(m.newFilter(append(withInfos, infos...), withoutInfos), func(a *archetype, row int, entity Entity) bool {
+		result[entity] = rows.fill(m, a, row, entity)
+		return true
+	})
+	return result, nil
+}
 
-	for _, t := range types {
-		if _, ok := m.components[t]; !ok {
+// lookupComponentTypes returns the registry entries of the types, or ErrComponentTypeNotFound if any of them is unknown
+func (m *Manager) lookupComponentTypes(types []string) ([]*componentInfo, error) {
+	infos := make([]*componentInfo, len(types))
+	for i, t := range types {
+		info, ok := m.registry.byName(t)
+		if !ok {
 			return nil, ErrComponentTypeNotFound
 		}
+		infos[i] = info
+	}
+	return infos, nil
+}
+
+// componentRowsChunk is the number of rows componentRows allocates at once
+const componentRowsChunk = 1024
 
-		for entity, component := range m.components[t] {
-			if existingComponents, ok := result[entity]; ok {
-				result[entity] = append(existingComponents, component)
-			} else {
-				result[entity] = []*Component{component}
+// componentRows hands out the component slices of a query result from a few large allocations. infos can hold nil for
+// types that are not known, whose components are nil
+type componentRows struct {
+	infos      []*componentInfo
+	components []Component
+	pointers   []*Component
+	// columns holds the column of each type in the archetype of the previous row, or -1 for types in sparse set storage
+	archetype *archetype
+	columns   []int
+}
+
+func newComponentRows(infos []*componentInfo) *componentRows {
+	return &componentRows{
+		infos:   infos,
+		columns: make([]int, len(infos)),
+	}
+}
+
+// fill returns the components of the entity, taken from the sparse sets or the row of the entity in its archetype
+func (r *componentRows) fill(m *Manager, a *archetype, row int, entity Entity) []*Component {
+	if a != r.archetype {
+		r.archetype = a
+		for i, info := range r.infos {
+			r.columns[i] = -1
+			if info != nil && info.set == nil {
+				r.columns[i] = a.column(info.ID)
 			}
 		}
 	}
 
-	// Filter out entities that do not have all the required types of components
-	for entity, components := range result {
-		componentTypes := make(map[string]bool)
-		for _, component := range components {
-			componentTypes[component.Type] = true
-		}
+	n := len(r.infos)
+	if cap(r.pointers)-len(r.pointers) < n {
+		// Earlier chunks stay referenced by the rows handed out from them
+		r.components = make([]Component, 0, componentRowsChunk*n)
+		r.pointers = make([]*Component, 0, componentRowsChunk*n)
+	}
 
-		for _, t := range types {
-			if !componentTypes[t] {
-				delete(result, entity)
-				break
-			}
+	start := len(r.pointers)
+	for i, info := range r.infos {
+		var data any
+		switch {
+		case r.columns[i] >= 0:
+			data = componentData(info, a.columns[r.columns[i]], row)
+		case info != nil && info.set != nil && info.set.has(entity):
+			data = componentData(info, info.set.column, info.set.dense(entity))
+		default:
+			// Optional components the entity does not have are nil
+			r.pointers = append(r.pointers, nil)
+			continue
 		}
+		r.components = append(r.components, Component{Type: info.Name, Data: data})
+		r.pointers = append(r.pointers, &r.components[len(r.components)-1])
 	}
-
-	return result, nil
+	return r.pointers[start:len(r.pointers):len(r.pointers)]
 }
 
-// GetComponentData returns the data of a component of the given type from a list of components
+// GetComponentData returns the data of a component of the given type from a list of components, which can hold nil
+// for optional components
 func GetComponentData[T any](components []*Component, componentType string) (*T, error) {
 	for _, component := range components {
-		if component.Type == componentType {
+		if component != nil && component.Type == componentType {
 			data, ok := component.Data.(T)
 			if !ok {
 				return nil, ErrComponentDataMismatch
@@ -1,6 +1,7 @@
 package ecs
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -18,6 +19,20 @@ type TestComponentNumber struct {
 
 const TestComponentNumberKey = "TestComponentNumber"
 
+// newTestManager creates a manager with entities 0 to count-1 and adds the given components to them
+func newTestManager(t *testing.T, count int, components map[string]map[Entity]*Component) *Manager {
+	m := NewManager()
+	for i := 0; i < count; i++ {
+		require.Equal(t, Entity(i), m.CreateEntity())
+	}
+	for _, entities := range components {
+		for e, c := range entities {
+			require.NoError(t, m.AddComponentToEntity(e, *c))
+		}
+	}
+	return m
+}
+
 func TestManager_Component_CRUD(t *testing.T) {
 	m := NewManager()
 	t.Log("Add component to entity - succeeds")
@@ -52,17 +67,15 @@ func Test_DeleteEntity(t *testing.T) {
 	}
 	t.Log("Delete existing entity - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-				},
+		m := newTestManager(t, 2, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
 			},
-		}
+		})
 		require.NoError(t, m.DeleteEntity(0))
 		c, err := m.GetComponentOfEntity(0, TestComponentStringKey)
-		require.ErrorIs(t, err, ErrComponentNotFound)
+		require.ErrorIs(t, err, ErrStaleEntity)
 		require.Nil(t, c)
 
 		c, err = m.GetComponentOfEntity(1, TestComponentStringKey)
@@ -70,15 +83,126 @@ func Test_DeleteEntity(t *testing.T) {
 		require.NotNil(t, c)
 		require.Equal(t, "World", (*c).Data.(TestComponentString).content)
 
-		t.Log("Create new entity after deleting existing entity - reuses ID")
+		t.Log("Create new entity after deleting existing entity - reuses index with a new generation")
 		{
 			e := m.CreateEntity()
-			require.Equal(t, Entity(0), e)
-			require.Len(t, m.freeIDs, 0)
+			require.Equal(t, uint32(0), e.Index())
+			require.Equal(t, uint32(1), e.Generation())
+			require.Equal(t, 0, m.freeIDs.Len())
+		}
+	}
+}
+
+func Test_StaleEntity(t *testing.T) {
+	m := NewManager()
+	stale := m.CreateEntity()
+	require.NoError(t, m.AddComponentToEntity(stale, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}}))
+	require.NoError(t, m.DeleteEntity(stale))
+
+	fresh := m.CreateEntity()
+	require.Equal(t, stale.Index(), fresh.Index())
+	require.NotEqual(t, stale, fresh)
+	require.NoError(t, m.AddComponentToEntity(fresh, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}}))
+
+	t.Log("Add component with stale handle - fails")
+	{
+		err := m.AddComponentToEntity(stale, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}})
+		require.ErrorIs(t, err, ErrStaleEntity)
+		_, err = m.GetComponentOfEntity(fresh, TestComponentNumberKey)
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+
+	t.Log("Get component with stale handle - fails")
+	{
+		c, err := m.GetComponentOfEntity(stale, TestComponentStringKey)
+		require.ErrorIs(t, err, ErrStaleEntity)
+		require.Nil(t, c)
+	}
+
+	t.Log("Delete component with stale handle - fails")
+	{
+		require.ErrorIs(t, m.DeleteComponentOfEntity(stale, TestComponentStringKey), ErrStaleEntity)
+		c, err := m.GetComponentOfEntity(fresh, TestComponentStringKey)
+		require.NoError(t, err)
+		require.Equal(t, "World", c.Data.(TestComponentString).content)
+	}
+
+	t.Log("Delete entity with stale handle - fails")
+	{
+		require.ErrorIs(t, m.DeleteEntity(stale), ErrStaleEntity)
+		require.NoError(t, m.DeleteEntity(fresh))
+		require.ErrorIs(t, m.DeleteEntity(fresh), ErrStaleEntity)
+	}
+}
+
+func Test_EntityRegistry(t *testing.T) {
+	m := NewManager()
+	t.Log("New manager has no entities")
+	{
+		require.Equal(t, 0, m.EntityCount())
+		require.False(t, m.IsAlive(0))
+	}
+
+	e0 := m.CreateEntity()
+	e1 := m.CreateEntity()
+	e2 := m.CreateEntity()
+	t.Log("Created entities are alive without components")
+	{
+		require.Equal(t, 3, m.EntityCount())
+		require.True(t, m.IsAlive(e0))
+		require.True(t, m.IsAlive(e1))
+		require.True(t, m.IsAlive(e2))
+		require.Equal(t, []Entity{e0, e1, e2}, slices.Collect(m.Entities()))
+	}
+
+	t.Log("Delete entity without components - succeeds")
+	{
+		require.NoError(t, m.DeleteEntity(e1))
+		require.False(t, m.IsAlive(e1))
+		require.Equal(t, 2, m.EntityCount())
+		require.Equal(t, []Entity{e0, e2}, slices.Collect(m.Entities()))
+
+		e := m.CreateEntity()
+		require.Equal(t, e1.Index(), e.Index())
+		require.True(t, m.IsAlive(e))
+		require.Equal(t, 3, m.EntityCount())
+	}
+
+	t.Log("Break out of entity iteration - stops early")
+	{
+		count := 0
+		for range m.Entities() {
+			count++
+			break
 		}
+		require.Equal(t, 1, count)
+	}
+
+	t.Log("Add component to never-created entity - fails")
+	{
+		err := m.AddComponentToEntity(Entity(42), Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}})
+		require.ErrorIs(t, err, ErrEntityNotFound)
+		_, err = m.GetEntitiesWithComponents([]string{TestComponentStringKey})
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+
+	t.Log("Add component to deleted entity - fails")
+	{
+		err := m.AddComponentToEntity(e1, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}})
+		require.ErrorIs(t, err, ErrStaleEntity)
 	}
 }
 
+func Test_EntityHandle(t *testing.T) {
+	e := newEntity(7, 3)
+	require.Equal(t, uint32(7), e.Index())
+	require.Equal(t, uint32(3), e.Generation())
+
+	e = newEntity(^uint32(0), ^uint32(0))
+	require.Equal(t, ^uint32(0), e.Index())
+	require.Equal(t, ^uint32(0), e.Generation())
+}
+
 func Test_AddComponentToEntity(t *testing.T) {
 	m := NewManager()
 	t.Log("Add component to entity - succeeds")
@@ -138,36 +262,31 @@ func Test_GetComponentsOfEntity(t *testing.T) {
 	t.Log("Get component of non-existent type - fails")
 	{
 		m := NewManager()
-		c, err := m.GetComponentOfEntity(0, "NonExistentType")
-		require.ErrorIs(t, ErrComponentTypeNotFound, err)
+		e := m.CreateEntity()
+		c, err := m.GetComponentOfEntity(e, "NonExistentType")
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
 		require.Nil(t, c)
 	}
 
 	t.Log("Get components of non-existent entity - fails")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-				},
+		m := newTestManager(t, 1, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
 			},
-			nextID: 1,
-		}
+		})
 		c, err := m.GetComponentOfEntity(999, TestComponentStringKey)
-		require.ErrorIs(t, ErrComponentNotFound, err)
+		require.ErrorIs(t, err, ErrEntityNotFound)
 		require.Nil(t, c)
 	}
 
 	t.Log("Get components of existing type and entity - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-				},
+		m := newTestManager(t, 1, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
 			},
-			nextID: 1,
-		}
+		})
 		c, err := m.GetComponentOfEntity(0, TestComponentStringKey)
 		require.NoError(t, err)
 		require.NotNil(t, c)
@@ -176,17 +295,14 @@ func Test_GetComponentsOfEntity(t *testing.T) {
 
 	t.Log("Get components of multiple types and entities - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-				},
+		m := newTestManager(t, 1, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
 			},
-			nextID: 1,
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+			},
+		})
 		cString, err := m.GetComponentOfEntity(0, TestComponentStringKey)
 		require.NoError(t, err)
 		require.NotNil(t, cString)
@@ -200,19 +316,16 @@ func Test_GetComponentsOfEntity(t *testing.T) {
 
 	t.Log("Get components of multiple types and entities - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-					1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
-				},
+		m := newTestManager(t, 2, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
 			},
-			nextID: 2,
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+				1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
+			},
+		})
 
 		cString0, err := m.GetComponentOfEntity(0, TestComponentStringKey)
 		require.NoError(t, err)
@@ -239,22 +352,20 @@ func Test_DeleteComponentsOfEntity(t *testing.T) {
 	t.Log("Delete components of non-existent type - fails")
 	{
 		m := NewManager()
-		require.ErrorIs(t, m.DeleteComponentOfEntity(0, "NonExistentType"), ErrComponentTypeNotFound)
-		c, err := m.GetComponentOfEntity(0, "NonExistentType")
+		e := m.CreateEntity()
+		require.ErrorIs(t, m.DeleteComponentOfEntity(e, "NonExistentType"), ErrComponentTypeNotFound)
+		c, err := m.GetComponentOfEntity(e, "NonExistentType")
 		require.ErrorIs(t, err, ErrComponentTypeNotFound)
 		require.Nil(t, c)
 	}
 
 	t.Log("Delete components of non-existent entity - no-op")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-				},
+		m := newTestManager(t, 1, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
 			},
-			nextID: 1,
-		}
+		})
 		m.DeleteComponentOfEntity(999, TestComponentStringKey)
 		c, err := m.GetComponentOfEntity(0, TestComponentStringKey)
 		require.NoError(t, err)
@@ -264,14 +375,11 @@ func Test_DeleteComponentsOfEntity(t *testing.T) {
 
 	t.Log("Delete components of existing type and entity - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-				},
+		m := newTestManager(t, 1, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
 			},
-			nextID: 1,
-		}
+		})
 
 		require.NoError(t, m.DeleteComponentOfEntity(0, TestComponentStringKey))
 		c, err := m.GetComponentOfEntity(0, TestComponentStringKey)
@@ -282,19 +390,16 @@ func Test_DeleteComponentsOfEntity(t *testing.T) {
 	t.Log("Delete components of multiple types and entities - succeeds")
 	{
 
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-					1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
-				},
+		m := newTestManager(t, 2, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
 			},
-			nextID: 2,
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+				1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
+			},
+		})
 
 		require.NoError(t, m.DeleteComponentOfEntity(0, TestComponentStringKey))
 		cString0, err := m.GetComponentOfEntity(0, TestComponentStringKey)
@@ -326,20 +431,18 @@ func Test_GetEntitiesWithComponents(t *testing.T) {
 	}
 	t.Log("Get components of multiple types - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-					3: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Foo"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-					1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
-					2: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 44}},
-				},
+		m := newTestManager(t, 4, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
+				3: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Foo"}},
 			},
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+				1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
+				2: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 44}},
+			},
+		})
 
 		ec, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, TestComponentNumberKey})
 		require.NoError(t, err)
@@ -355,24 +458,32 @@ func Test_GetEntitiesWithComponents(t *testing.T) {
 		require.Equal(t, "World", ec[1][0].Data.(TestComponentString).content)
 		require.Equal(t, 43, ec[1][1].Data.(TestComponentNumber).content)
 	}
-
+	t.Log("Get components of no types - returns no entities")
+	{
+		m := NewManager()
+		m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(m.CreateEntity(), Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}}))
+		for _, types := range [][]string{nil, {}} {
+			ec, err := m.GetEntitiesWithComponents(types)
+			require.NoError(t, err)
+			require.NotNil(t, ec)
+			require.Empty(t, ec)
+		}
+	}
 }
 func Test_GetComponentData(t *testing.T) {
 	t.Log("Get component data of existing type - succeeds")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-					1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
-				},
+		m := newTestManager(t, 2, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
 			},
-			nextID: 1,
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+				1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
+			},
+		})
 
 		entitiesWithComponents, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, TestComponentNumberKey})
 		require.NoError(t, err)
@@ -391,19 +502,16 @@ func Test_GetComponentData(t *testing.T) {
 
 	t.Log("Get component data of non-existent type - fails")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-					1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
-				},
+		m := newTestManager(t, 2, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
 			},
-			nextID: 1,
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+				1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
+			},
+		})
 
 		entitiesWithComponents, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, TestComponentNumberKey})
 		require.NoError(t, err)
@@ -416,19 +524,16 @@ func Test_GetComponentData(t *testing.T) {
 
 	t.Log("Get component data with type mismatch - fails")
 	{
-		m := Manager{
-			components: map[string]map[Entity]*Component{
-				TestComponentStringKey: {
-					0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
-					1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
-				},
-				TestComponentNumberKey: {
-					0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
-					1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
-				},
+		m := newTestManager(t, 2, map[string]map[Entity]*Component{
+			TestComponentStringKey: {
+				0: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}},
+				1: &Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}},
 			},
-			nextID: 1,
-		}
+			TestComponentNumberKey: {
+				0: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}},
+				1: &Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 43}},
+			},
+		})
 
 		entitiesWithComponents, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, TestComponentNumberKey})
 		require.NoError(t, err)
@@ -480,3 +585,107 @@ func Test_GetDataAsType(t *testing.T) {
 		require.Equal(t, "Hello", data.content)
 	}
 }
+
+func Test_RegisterComponentType(t *testing.T) {
+	t.Log("Register type - succeeds and makes the type known")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.NoError(t, m.RegisterComponentType(TestComponentStringKey, SparseSetStorage))
+		require.NoError(t, m.RegisterComponentType(TestComponentNumberKey, TableStorage))
+
+		_, err := m.GetComponentOfEntity(e, TestComponentStringKey)
+		require.ErrorIs(t, err, ErrComponentNotFound)
+		_, err = m.GetComponentOfEntity(e, TestComponentNumberKey)
+		require.ErrorIs(t, err, ErrComponentNotFound)
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, TestComponentNumberKey})
+		require.NoError(t, err)
+		require.Empty(t, ec)
+	}
+
+	t.Log("Register type again with the same storage - succeeds")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType(TestComponentStringKey, SparseSetStorage))
+		require.NoError(t, m.RegisterComponentType(TestComponentStringKey, SparseSetStorage))
+	}
+
+	t.Log("Register type with a different storage - fails")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType(TestComponentStringKey, SparseSetStorage))
+		require.ErrorIs(t, m.RegisterComponentType(TestComponentStringKey, TableStorage), ErrStorageTypeMismatch)
+
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: 42}}))
+		require.ErrorIs(t, m.RegisterComponentType(TestComponentNumberKey, SparseSetStorage), ErrStorageTypeMismatch)
+	}
+}
+
+func Test_SparseSetStorage(t *testing.T) {
+	m := NewManager()
+	require.NoError(t, m.RegisterComponentType(TestComponentStringKey, SparseSetStorage))
+	entities := make([]Entity, 4)
+	for i := range entities {
+		entities[i] = m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(entities[i], Component{Type: TestComponentNumberKey, Data: TestComponentNumber{content: i}}))
+	}
+
+	t.Log("Add sparse set component - does not move the entity between archetypes")
+	{
+		a := m.entities[entities[1].Index()].archetype
+		require.NoError(t, m.AddComponentToEntity(entities[1], Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}}))
+		require.NoError(t, m.AddComponentToEntity(entities[3], Component{Type: TestComponentStringKey, Data: TestComponentString{content: "World"}}))
+		require.Same(t, a, m.entities[entities[1].Index()].archetype)
+
+		c, err := m.GetComponentOfEntity(entities[1], TestComponentStringKey)
+		require.NoError(t, err)
+		require.Equal(t, "Hello", c.Data.(TestComponentString).content)
+		_, err = m.GetComponentOfEntity(entities[0], TestComponentStringKey)
+		require.ErrorIs(t, err, ErrComponentNotFound)
+	}
+
+	t.Log("Get entities with table and sparse set components - succeeds")
+	{
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey, TestComponentNumberKey})
+		require.NoError(t, err)
+		require.Len(t, ec, 2)
+		require.Equal(t, "Hello", ec[entities[1]][0].Data.(TestComponentString).content)
+		require.Equal(t, 1, ec[entities[1]][1].Data.(TestComponentNumber).content)
+		require.Equal(t, "World", ec[entities[3]][0].Data.(TestComponentString).content)
+		require.Equal(t, 3, ec[entities[3]][1].Data.(TestComponentNumber).content)
+
+		ec, err = m.GetEntitiesWithComponents([]string{TestComponentStringKey})
+		require.NoError(t, err)
+		require.Len(t, ec, 2)
+	}
+
+	t.Log("Get entities with sparse set components missing a table component - filters them out")
+	{
+		require.NoError(t, m.DeleteComponentOfEntity(entities[3], TestComponentNumberKey))
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentNumberKey, TestComponentStringKey})
+		require.NoError(t, err)
+		require.Len(t, ec, 1)
+		require.Contains(t, ec, entities[1])
+	}
+
+	t.Log("Delete sparse set component - succeeds")
+	{
+		require.NoError(t, m.DeleteComponentOfEntity(entities[1], TestComponentStringKey))
+		require.ErrorIs(t, m.DeleteComponentOfEntity(entities[1], TestComponentStringKey), ErrComponentNotFound)
+		_, err := m.GetComponentOfEntity(entities[1], TestComponentStringKey)
+		require.ErrorIs(t, err, ErrComponentNotFound)
+	}
+
+	t.Log("Delete entity - removes its sparse set components")
+	{
+		require.NoError(t, m.DeleteEntity(entities[3]))
+		e := m.CreateEntity()
+		require.Equal(t, entities[3].Index(), e.Index())
+		_, err := m.GetComponentOfEntity(e, TestComponentStringKey)
+		require.ErrorIs(t, err, ErrComponentNotFound)
+		ec, err := m.GetEntitiesWithComponents([]string{TestComponentStringKey})
+		require.NoError(t, err)
+		require.Empty(t, ec)
+	}
+}
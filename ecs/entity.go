@@ -0,0 +1,44 @@
+package ecs
+
+// entityIndexBits is the number of low bits of an Entity that hold its index, the remaining high bits hold its
+// generation
+const entityIndexBits = 32
+
+// Entity acts as a container of components, it is a handle made up of an index and a generation.
+// The index identifies a slot in the Manager, the generation is bumped every time that slot is freed,
+// so handles kept around after their entity was deleted can be told apart from the entity reusing the slot
+type Entity uint64
+
+func newEntity(index uint32, generation uint32) Entity {
+	return Entity(uint64(generation)<<entityIndexBits | uint64(index))
+}
+
+// Index returns the slot of the entity in the Manager
+func (e Entity) Index() uint32 {
+	return uint32(e)
+}
+
+// Generation returns how many times the slot of the entity had been recycled when the handle was created
+func (e Entity) Generation() uint32 {
+	return uint32(e >> entityIndexBits)
+}
+
+// entityRecord is the bookkeeping the Manager keeps for every entity index
+type entityRecord struct {
+	// generation is bumped every time the index is freed
+	generation uint32
+	// alive is true between the creation and the deletion of the entity using the index
+	alive bool
+	// archetype is the archetype storing the components of the entity, and row is the row of the entity in it
+	archetype *archetype
+	row       int
+	// signature holds the IDs of all component types of the entity, in table and in sparse set storage
+	signature Signature
+	// parent is the parent of the entity if hasParent is true, children holds the children of the entity in the order
+	// they were attached
+	parent    Entity
+	hasParent bool
+	children  []Entity
+	// name is the unique name of the entity, or empty if it has none
+	name string
+}
@@ -0,0 +1,117 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// eventBuffer is the part of an eventQueue that does not depend on the event type
+type eventBuffer interface {
+	// update drops the events of the previous tick and starts a new tick
+	update()
+}
+
+// eventQueue is a double buffer holding the events of type T sent during the current and the previous world tick.
+// Every event has an ID, counting the events of the type sent since the Manager was created
+type eventQueue[T any] struct {
+	previous []T
+	current  []T
+	// start is the ID of the first event in previous, the events in current follow the ones in previous
+	start uint64
+}
+
+func (q *eventQueue[T]) update() {
+	q.start += uint64(len(q.previous))
+	// Reuse the storage of the dropped events for the next tick, clearing it so it does not keep their data alive
+	clear(q.previous)
+	q.previous, q.current = q.current, q.previous[:0]
+}
+
+// end returns the ID the next event will get
+func (q *eventQueue[T]) end() uint64 {
+	return q.start + uint64(len(q.previous)+len(q.current))
+}
+
+// at returns the event with the given ID, which must be held by the queue
+func (q *eventQueue[T]) at(id uint64) T {
+	if i := id - q.start; i < uint64(len(q.previous)) {
+		return q.previous[i]
+	}
+	return q.current[id-q.start-uint64(len(q.previous))]
+}
+
+// EventReader is the cursor of a reader of events of type T, every reader owns one and reads each event once. The zero
+// value reads all events still held by the Manager
+type EventReader[T any] struct {
+	// next is the ID of the next event to read
+	next uint64
+}
+
+// eventQueueOf returns the queue of events of type T, or nil if no event of type T has been sent
+func eventQueueOf[T any](m *Manager) *eventQueue[T] {
+	buffer, ok := m.events[reflect.TypeFor[T]()]
+	if !ok {
+		return nil
+	}
+	return buffer.(*eventQueue[T])
+}
+
+// Send sends an event of type T to all readers of the type. Events are dropped after two calls to Tick, so readers
+// that read at least once per Tick see all of them
+func Send[T any](m *Manager, event T) {
+	q := eventQueueOf[T](m)
+	if q == nil {
+		q = &eventQueue[T]{}
+		m.events[reflect.TypeFor[T]()] = q
+	}
+	q.current = append(q.current, event)
+}
+
+// Read returns an iterator over the events of type T the reader has not read yet, in the order they were sent,
+// advancing the cursor of the reader past every event it yields. Breaking out of the loop leaves the remaining events
+// to be read next time, and events sent while iterating are read next time as well. Events dropped before the reader
+// got to them are skipped
+func Read[T any](m *Manager, reader *EventReader[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		q := eventQueueOf[T](m)
+		if q == nil {
+			return
+		}
+		reader.next = max(reader.next, q.start)
+		for end := q.end(); reader.next < end; {
+			event := q.at(reader.next)
+			reader.next++
+			if !yield(event) {
+				return
+			}
+		}
+	}
+}
+
+// ReadSlices works like Read, but returns all unread events at once as two slices, the events of the previous tick
+// followed by the events of the current tick. The slices point into the storage of the Manager and are only valid
+// until the next call to Send or Tick
+func ReadSlices[T any](m *Manager, reader *EventReader[T]) ([]T, []T) {
+	q := eventQueueOf[T](m)
+	if q == nil {
+		return nil, nil
+	}
+	next := max(reader.next, q.start)
+	reader.next = q.end()
+
+	split := q.start + uint64(len(q.previous))
+	if next >= split {
+		return nil, q.current[next-split:]
+	}
+	return q.previous[next-q.start:], q.current
+}
+
+// ClearEvents drops all events of type T, readers skip them
+func ClearEvents[T any](m *Manager) {
+	q := eventQueueOf[T](m)
+	if q == nil {
+		return
+	}
+	q.update()
+	q.update()
+}
@@ -0,0 +1,125 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCollisionEvent struct {
+	A, B Entity
+}
+
+type testDamageEvent struct {
+	Target Entity
+	Amount int
+}
+
+func Test_Events(t *testing.T) {
+	t.Log("Send and read events with several readers - succeeds")
+	{
+		m := NewManager()
+		var physics, audio EventReader[testCollisionEvent]
+		require.Empty(t, slices.Collect(Read(m, &physics)))
+
+		Send(m, testCollisionEvent{A: 1, B: 2})
+		Send(m, testCollisionEvent{A: 3, B: 4})
+		Send(m, testDamageEvent{Target: 1, Amount: 10})
+		require.Equal(t, []testCollisionEvent{{A: 1, B: 2}, {A: 3, B: 4}}, slices.Collect(Read(m, &physics)))
+		require.Empty(t, slices.Collect(Read(m, &physics)))
+
+		m.Tick()
+		Send(m, testCollisionEvent{A: 5, B: 6})
+		require.Equal(t, []testCollisionEvent{{A: 5, B: 6}}, slices.Collect(Read(m, &physics)))
+		require.Equal(t, []testCollisionEvent{{A: 1, B: 2}, {A: 3, B: 4}, {A: 5, B: 6}}, slices.Collect(Read(m, &audio)))
+
+		var damage EventReader[testDamageEvent]
+		require.Equal(t, []testDamageEvent{{Target: 1, Amount: 10}}, slices.Collect(Read(m, &damage)))
+	}
+
+	t.Log("Events are dropped after two ticks - succeeds")
+	{
+		m := NewManager()
+		var early, late EventReader[testCollisionEvent]
+		Send(m, testCollisionEvent{A: 1})
+		m.Tick()
+		Send(m, testCollisionEvent{A: 2})
+		require.Len(t, slices.Collect(Read(m, &early)), 2)
+		m.Tick()
+		Send(m, testCollisionEvent{A: 3})
+		require.Equal(t, []testCollisionEvent{{A: 3}}, slices.Collect(Read(m, &early)))
+		require.Equal(t, []testCollisionEvent{{A: 2}, {A: 3}}, slices.Collect(Read(m, &late)))
+		m.Tick()
+		m.Tick()
+		require.Empty(t, slices.Collect(Read(m, &late)))
+	}
+
+	t.Log("Break out of reading - leaves the remaining events unread")
+	{
+		m := NewManager()
+		var reader EventReader[testCollisionEvent]
+		for i := range 3 {
+			Send(m, testCollisionEvent{A: Entity(i)})
+		}
+		for event := range Read(m, &reader) {
+			require.Equal(t, Entity(0), event.A)
+			Send(m, testCollisionEvent{A: 3})
+			break
+		}
+		require.Equal(t, []testCollisionEvent{{A: 1}, {A: 2}, {A: 3}}, slices.Collect(Read(m, &reader)))
+	}
+
+	t.Log("Read events as slices - succeeds")
+	{
+		m := NewManager()
+		var reader EventReader[testCollisionEvent]
+		previous, current := ReadSlices(m, &reader)
+		require.Empty(t, previous)
+		require.Empty(t, current)
+		Send(m, testCollisionEvent{A: 1})
+		Send(m, testCollisionEvent{A: 2})
+		m.Tick()
+		Send(m, testCollisionEvent{A: 3})
+		for range Read(m, &reader) {
+			break
+		}
+		previous, current = ReadSlices(m, &reader)
+		require.Equal(t, []testCollisionEvent{{A: 2}}, previous)
+		require.Equal(t, []testCollisionEvent{{A: 3}}, current)
+		previous, current = ReadSlices(m, &reader)
+		require.Empty(t, previous)
+		require.Empty(t, current)
+	}
+
+	t.Log("Clear events - readers skip them")
+	{
+		m := NewManager()
+		var reader EventReader[testCollisionEvent]
+		Send(m, testCollisionEvent{A: 1})
+		m.Tick()
+		Send(m, testCollisionEvent{A: 2})
+		ClearEvents[testCollisionEvent](m)
+		require.Empty(t, slices.Collect(Read(m, &reader)))
+		Send(m, testCollisionEvent{A: 3})
+		require.Equal(t, []testCollisionEvent{{A: 3}}, slices.Collect(Read(m, &reader)))
+	}
+
+	t.Log("Send and read events - does not allocate once buffers are grown")
+	{
+		m := NewManager()
+		var reader EventReader[testCollisionEvent]
+		frame := func() {
+			for i := range 100 {
+				Send(m, testCollisionEvent{A: Entity(i)})
+			}
+			for event := range Read(m, &reader) {
+				_ = event
+			}
+			m.Tick()
+		}
+		frame()
+		frame()
+		require.Zero(t, testing.AllocsPerRun(10, frame))
+	}
+}
@@ -0,0 +1,116 @@
+package ecs
+
+// filter is a compiled query, it matches the signatures that hold all of its required component types, none of its
+// excluded ones, and at least one type of each of its anyOf groups
+type filter struct {
+	required Signature
+	excluded Signature
+	anyOf    []Signature
+	// archetypeRequired and archetypeExcluded only hold the component types in table storage, which are the ones that
+	// make up the signatures of archetypes
+	archetypeRequired Signature
+	archetypeExcluded Signature
+	// checkEntities is true when the filter has component types in sparse set storage, in which case the entities of
+	// a matching archetype still need to be matched one by one
+	checkEntities bool
+}
+
+// newFilter compiles the required and excluded component types into a filter
+func (m *Manager) newFilter(required []*componentInfo, excluded []*componentInfo) *filter {
+	f := &filter{}
+	for _, info := range required {
+		f.required.Set(info.ID)
+	}
+	for _, info := range excluded {
+		f.excluded.Set(info.ID)
+	}
+	f.archetypeRequired = f.required.without(m.sparseTypes)
+	f.archetypeExcluded = f.excluded.without(m.sparseTypes)
+	f.checkEntities = f.required.Intersects(m.sparseTypes) || f.excluded.Intersects(m.sparseTypes)
+	return f
+}
+
+// addAnyOf adds a group of component types of which a matching signature must hold at least one
+func (m *Manager) addAnyOf(f *filter, infos []*componentInfo) {
+	var group Signature
+	for _, info := range infos {
+		group.Set(info.ID)
+	}
+	f.anyOf = append(f.anyOf, group)
+	f.checkEntities = f.checkEntities || group.Intersects(m.sparseTypes)
+}
+
+func (f *filter) matches(s Signature) bool {
+	if !s.ContainsAll(f.required) || s.Intersects(f.excluded) {
+		return false
+	}
+	for _, group := range f.anyOf {
+		if !s.Intersects(group) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesArchetype returns true if entities in the archetype can match the filter, entities still need to be matched
+// one by one if checkEntities is true
+func (f *filter) matchesArchetype(a *archetype) bool {
+	if !a.signature.ContainsAll(f.archetypeRequired) || a.signature.Intersects(f.archetypeExcluded) {
+		return false
+	}
+	for _, group := range f.anyOf {
+		// Entities of the archetype can still have a type of the group in sparse set storage
+		if !f.checkEntities && !a.signature.Intersects(group) {
+			return false
+		}
+	}
+	return true
+}
+
+// eachMatch calls fn with every entity matching the filter, along with the archetype and row holding its table
+// components, until fn returns false
+func (m *Manager) eachMatch(f *filter, fn func(a *archetype, row int, entity Entity) bool) {
+	// Only the entities in the smallest required sparse set, or the archetypes containing the rarest required table
+	// type, can match
+	var driver *sparseSet
+	candidates := m.archetypes
+	for id := range f.required.IDs() {
+		info := m.registry.components[id]
+		if info.set != nil {
+			if driver == nil || info.set.len() < driver.len() {
+				driver = info.set
+			}
+			continue
+		}
+		if len(m.componentIndex[id]) < len(candidates) {
+			candidates = m.componentIndex[id]
+		}
+	}
+
+	if driver != nil {
+		for _, entity := range driver.entities {
+			record := m.entities[entity.Index()]
+			if !f.matches(record.signature) {
+				continue
+			}
+			if !fn(record.archetype, record.row, entity) {
+				return
+			}
+		}
+		return
+	}
+
+	for _, a := range candidates {
+		if !f.matchesArchetype(a) {
+			continue
+		}
+		for row, entity := range a.entities {
+			if f.checkEntities && !f.matches(m.entities[entity.Index()].signature) {
+				continue
+			}
+			if !fn(a, row, entity) {
+				return
+			}
+		}
+	}
+}
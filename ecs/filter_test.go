@@ -0,0 +1,68 @@
+package ecs
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Filter(t *testing.T) {
+	m := NewManager()
+	require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+	infos := make([]*componentInfo, 0)
+	// Register more than 64 component types so signatures span several words
+	for i := 0; i < 100; i++ {
+		require.NoError(t, m.RegisterComponentType(fmt.Sprintf("Type%d", i), TableStorage))
+		info, ok := m.registry.byName(fmt.Sprintf("Type%d", i))
+		require.True(t, ok)
+		infos = append(infos, info)
+	}
+	sparse, ok := m.registry.byName("Sparse")
+	require.True(t, ok)
+
+	entities := make([]Entity, 4)
+	for i := range entities {
+		entities[i] = m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(entities[i], Component{Type: "Type99"}))
+	}
+	require.NoError(t, m.AddComponentToEntity(entities[1], Component{Type: "Type3"}))
+	require.NoError(t, m.AddComponentToEntity(entities[2], Component{Type: "Type70"}))
+	require.NoError(t, m.AddComponentToEntity(entities[3], Component{Type: "Sparse"}))
+
+	match := func(f *filter) []Entity {
+		result := make([]Entity, 0)
+		m.eachMatch(f, func(a *archetype, row int, entity Entity) bool {
+			result = append(result, entity)
+			return true
+		})
+		slices.Sort(result)
+		return result
+	}
+
+	t.Log("Required types - matches entities having all of them")
+	{
+		require.Equal(t, entities, match(m.newFilter([]*componentInfo{infos[99]}, nil)))
+		require.Equal(t, []Entity{entities[2]}, match(m.newFilter([]*componentInfo{infos[99], infos[70]}, nil)))
+		require.Equal(t, []Entity{entities[3]}, match(m.newFilter([]*componentInfo{sparse, infos[99]}, nil)))
+		require.Empty(t, match(m.newFilter([]*componentInfo{infos[3], infos[70]}, nil)))
+	}
+
+	t.Log("Excluded types - filters out entities having any of them")
+	{
+		require.Equal(t, []Entity{entities[0], entities[3]}, match(m.newFilter([]*componentInfo{infos[99]}, []*componentInfo{infos[3], infos[70]})))
+		require.Equal(t, []Entity{entities[0], entities[1], entities[2]}, match(m.newFilter([]*componentInfo{infos[99]}, []*componentInfo{sparse})))
+		require.Empty(t, match(m.newFilter([]*componentInfo{sparse}, []*componentInfo{infos[99]})))
+	}
+
+	t.Log("Stop iteration early - succeeds")
+	{
+		count := 0
+		m.eachMatch(m.newFilter([]*componentInfo{infos[99]}, nil), func(a *archetype, row int, entity Entity) bool {
+			count++
+			return false
+		})
+		require.Equal(t, 1, count)
+	}
+}
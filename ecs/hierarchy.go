@@ -0,0 +1,193 @@
+package ecs
+
+import (
+	"errors"
+	"iter"
+	"slices"
+)
+
+var ErrHierarchyCycle = errors.New("entity cannot be the parent of itself or of one of its ancestors")
+
+// SetParent makes parent the parent of child, detaching child from its previous parent. It returns ErrHierarchyCycle
+// if child is parent or one of its ancestors
+func (m *Manager) SetParent(child Entity, parent Entity) error {
+	if err := m.checkEntity(child); err != nil {
+		return err
+	}
+	if err := m.checkEntity(parent); err != nil {
+		return err
+	}
+	if child == parent {
+		return ErrHierarchyCycle
+	}
+	for ancestor := range m.Ancestors(parent) {
+		if ancestor == child {
+			return ErrHierarchyCycle
+		}
+	}
+
+	m.detachParent(child)
+	record := &m.entities[child.Index()]
+	record.parent = parent
+	record.hasParent = true
+	parentRecord := &m.entities[parent.Index()]
+	parentRecord.children = append(parentRecord.children, child)
+	return nil
+}
+
+// RemoveParent detaches the entity from its parent, making it a root. Entities without a parent are left unchanged
+func (m *Manager) RemoveParent(entity Entity) error {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	m.detachParent(entity)
+	return nil
+}
+
+// Parent returns the parent of the entity, or false if the entity has no parent or is not alive
+func (m *Manager) Parent(entity Entity) (Entity, bool) {
+	if m.checkEntity(entity) != nil {
+		return 0, false
+	}
+	record := m.entities[entity.Index()]
+	return record.parent, record.hasParent
+}
+
+// Children returns an iterator over the children of the entity in the order they were attached. The hierarchy must not
+// be changed while iterating, use Descendants to walk a tree that is changed on the way
+func (m *Manager) Children(entity Entity) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		if m.checkEntity(entity) != nil {
+			return
+		}
+		for _, child := range m.entities[entity.Index()].children {
+			if !yield(child) {
+				return
+			}
+		}
+	}
+}
+
+// Ancestors returns an iterator over the parent of the entity, the parent of its parent and so on up to the root
+func (m *Manager) Ancestors(entity Entity) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		for parent, ok := m.Parent(entity); ok; parent, ok = m.Parent(parent) {
+			if !yield(parent) {
+				return
+			}
+		}
+	}
+}
+
+// Descendants returns an iterator over the descendants of the entity, depth-first with every entity before its
+// children. The children of an entity are collected when the entity is yielded, so changes made to the hierarchy
+// below the yielded entity are seen by the iterator
+func (m *Manager) Descendants(entity Entity) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		stopped := false
+		m.walk(entity, func(e Entity, _ int) bool {
+			if stopped {
+				return false
+			}
+			stopped = e != entity && !yield(e)
+			return !stopped
+		})
+	}
+}
+
+// WalkHierarchy visits root and its descendants depth-first, with every entity before its children, and calls fn for
+// each of them that has all types of components, passing its depth below root and its components in the order of the
+// types. Entities without all types are not passed to fn but their descendants are visited. When fn returns false the
+// descendants of the entity are skipped. The components are copies, changes to them are only stored by passing them to
+// AddComponentToEntity
+func (m *Manager) WalkHierarchy(root Entity, types []string, fn func(entity Entity, depth int, components []*Component) bool) error {
+	if err := m.checkEntity(root); err != nil {
+		return err
+	}
+	infos, err := m.lookupComponentTypes(types)
+	if err != nil {
+		return err
+	}
+	f := m.newFilter(infos, nil)
+
+	m.walk(root, func(entity Entity, depth int) bool {
+		if !f.matches(m.entities[entity.Index()].signature) {
+			return true
+		}
+		components := make([]*Component, len(infos))
+		for i, info := range infos {
+			c, row, _ := m.componentRow(entity, info)
+			components[i] = &Component{Type: info.Name, Data: componentData(info, c, row)}
+		}
+		return fn(entity, depth, components)
+	})
+	return nil
+}
+
+// walk visits root and its live descendants depth-first, calling visit with each entity and its depth below root. When
+// visit returns false the descendants of the entity are skipped
+func (m *Manager) walk(root Entity, visit func(entity Entity, depth int) bool) {
+	type frame struct {
+		entity Entity
+		depth  int
+	}
+	stack := []frame{{entity: root}}
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if m.checkEntity(top.entity) != nil {
+			continue
+		}
+		if !visit(top.entity, top.depth) {
+			continue
+		}
+		children := m.entities[top.entity.Index()].children
+		for i := len(children) - 1; i >= 0; i-- {
+			stack = append(stack, frame{entity: children[i], depth: top.depth + 1})
+		}
+	}
+}
+
+// DeleteEntityRecursive deletes the entity and all its descendants, see DeleteEntity. Entities are deleted depth-first
+// with every entity before its children, which is also the order their indices are handed to the IDRecycler
+func (m *Manager) DeleteEntityRecursive(entity Entity) error {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	entities := []Entity{entity}
+	for descendant := range m.Descendants(entity) {
+		entities = append(entities, descendant)
+	}
+	for _, e := range entities {
+		m.deleteEntity(e)
+	}
+	m.runHooks()
+	return nil
+}
+
+// detachParent removes the entity from the children of its parent
+func (m *Manager) detachParent(entity Entity) {
+	record := &m.entities[entity.Index()]
+	if !record.hasParent {
+		return
+	}
+	parentRecord := &m.entities[record.parent.Index()]
+	if i := slices.Index(parentRecord.children, entity); i >= 0 {
+		parentRecord.children = slices.Delete(parentRecord.children, i, i+1)
+	}
+	record.parent = 0
+	record.hasParent = false
+}
+
+// detachEntity removes a deleted entity from the hierarchy, its children become roots
+func (m *Manager) detachEntity(entity Entity) {
+	m.detachParent(entity)
+	record := &m.entities[entity.Index()]
+	for _, child := range record.children {
+		childRecord := &m.entities[child.Index()]
+		childRecord.parent = 0
+		childRecord.hasParent = false
+	}
+	clear(record.children)
+	record.children = record.children[:0]
+}
@@ -0,0 +1,170 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHierarchy creates a ship with two turrets, the first turret having a muzzle flash
+func newTestHierarchy(t *testing.T, m *Manager) (ship, turret1, turret2, flash Entity) {
+	t.Helper()
+	ship = m.CreateEntity()
+	turret1 = m.CreateEntity()
+	turret2 = m.CreateEntity()
+	flash = m.CreateEntity()
+	require.NoError(t, m.SetParent(turret1, ship))
+	require.NoError(t, m.SetParent(turret2, ship))
+	require.NoError(t, m.SetParent(flash, turret1))
+	return ship, turret1, turret2, flash
+}
+
+func Test_SetParent(t *testing.T) {
+	t.Log("Set parent - succeeds")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		parent, ok := m.Parent(flash)
+		require.True(t, ok)
+		require.Equal(t, turret1, parent)
+		_, ok = m.Parent(ship)
+		require.False(t, ok)
+		require.Equal(t, []Entity{turret1, turret2}, slices.Collect(m.Children(ship)))
+		require.Equal(t, []Entity{turret1, ship}, slices.Collect(m.Ancestors(flash)))
+	}
+
+	t.Log("Move child to another parent - succeeds")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		require.NoError(t, m.SetParent(flash, turret2))
+		require.Empty(t, slices.Collect(m.Children(turret1)))
+		require.Equal(t, []Entity{flash}, slices.Collect(m.Children(turret2)))
+		require.NoError(t, m.RemoveParent(turret1))
+		require.Equal(t, []Entity{turret2}, slices.Collect(m.Children(ship)))
+		_, ok := m.Parent(turret1)
+		require.False(t, ok)
+	}
+
+	t.Log("Set parent creating a cycle - fails")
+	{
+		m := NewManager()
+		ship, _, _, flash := newTestHierarchy(t, m)
+		require.ErrorIs(t, m.SetParent(ship, flash), ErrHierarchyCycle)
+		require.ErrorIs(t, m.SetParent(ship, ship), ErrHierarchyCycle)
+	}
+
+	t.Log("Set parent of deleted entity - fails")
+	{
+		m := NewManager()
+		child := m.CreateEntity()
+		parent := m.CreateEntity()
+		require.NoError(t, m.DeleteEntity(parent))
+		require.ErrorIs(t, m.SetParent(child, parent), ErrStaleEntity)
+	}
+}
+
+func Test_DeleteEntityHierarchy(t *testing.T) {
+	t.Log("Delete parent - children become roots")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		require.NoError(t, m.DeleteEntity(turret1))
+		_, ok := m.Parent(flash)
+		require.False(t, ok)
+		require.Equal(t, []Entity{turret2}, slices.Collect(m.Children(ship)))
+	}
+
+	t.Log("Delete entity recursively - succeeds")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		other := m.CreateEntity()
+		require.NoError(t, m.SetParent(other, ship))
+		require.NoError(t, m.RemoveParent(other))
+
+		require.NoError(t, m.DeleteEntityRecursive(ship))
+		for _, e := range []Entity{ship, turret1, turret2, flash} {
+			require.False(t, m.IsAlive(e))
+		}
+		require.True(t, m.IsAlive(other))
+		require.Equal(t, 1, m.EntityCount())
+		require.Equal(t, 4, m.freeIDs.Len())
+
+		// Indices are handed to the recycler depth-first
+		entities, err := m.CreateEntities(4)
+		require.NoError(t, err)
+		indices := make([]uint32, len(entities))
+		for i, e := range entities {
+			indices[i] = e.Index()
+		}
+		require.Equal(t, []uint32{ship.Index(), turret1.Index(), flash.Index(), turret2.Index()}, indices)
+		for _, e := range entities {
+			_, ok := m.Parent(e)
+			require.False(t, ok)
+			require.Empty(t, slices.Collect(m.Children(e)))
+		}
+	}
+
+	t.Log("Delete subtree recursively - detaches it from its parent")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		require.NoError(t, m.DeleteEntityRecursive(turret1))
+		require.False(t, m.IsAlive(flash))
+		require.Equal(t, []Entity{turret2}, slices.Collect(m.Children(ship)))
+	}
+}
+
+func Test_WalkHierarchy(t *testing.T) {
+	t.Log("Walk descendants depth-first - succeeds")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		require.Equal(t, []Entity{turret1, flash, turret2}, slices.Collect(m.Descendants(ship)))
+		for e := range m.Descendants(ship) {
+			require.Equal(t, turret1, e)
+			break
+		}
+	}
+
+	t.Log("Walk hierarchy with components - succeeds")
+	{
+		m := NewManager()
+		ship, turret1, turret2, flash := newTestHierarchy(t, m)
+		for _, e := range []Entity{ship, turret2, flash} {
+			require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: int(e)}))
+		}
+
+		visited := make([]Entity, 0)
+		depths := make([]int, 0)
+		err := m.WalkHierarchy(ship, []string{"Health"}, func(entity Entity, depth int, components []*Component) bool {
+			require.Equal(t, int(entity), components[0].Data)
+			visited = append(visited, entity)
+			depths = append(depths, depth)
+			return true
+		})
+		require.NoError(t, err)
+		require.Equal(t, []Entity{ship, flash, turret2}, visited)
+		require.Equal(t, []int{0, 2, 1}, depths)
+
+		// Returning false skips the descendants
+		visited = visited[:0]
+		require.NoError(t, m.AddComponentToEntity(turret1, Component{Type: "Health", Data: int(turret1)}))
+		err = m.WalkHierarchy(ship, []string{"Health"}, func(entity Entity, _ int, _ []*Component) bool {
+			visited = append(visited, entity)
+			return entity != turret1
+		})
+		require.NoError(t, err)
+		require.Equal(t, []Entity{ship, turret1, turret2}, visited)
+	}
+
+	t.Log("Walk hierarchy with unknown component type - fails")
+	{
+		m := NewManager()
+		ship, _, _, _ := newTestHierarchy(t, m)
+		err := m.WalkHierarchy(ship, []string{"Unknown"}, func(Entity, int, []*Component) bool { return true })
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+}
@@ -0,0 +1,142 @@
+package ecs
+
+// Hook reacts to a change of the component of an entity. old is nil when the component is added, and new is nil when
+// it is removed. Changes made through the pointers returned by ComponentStore.Get do not run hooks.
+//
+// Hooks run once the call that triggered them has completed, so they always see a consistent world and may change it
+// freely, but the component may have changed again by the time a hook runs. Changes made by a hook do not run their
+// hooks right away: they are queued and run after the current hook returns, in the order they were triggered, before
+// the outermost call returns. Hooks that keep triggering each other never return
+type Hook func(m *Manager, entity Entity, old any, new any)
+
+// componentHooks holds the hooks of a component type in order of registration
+type componentHooks struct {
+	onAdd    []Hook
+	onSet    []Hook
+	onRemove []Hook
+}
+
+// hookCall is a queued run of hooks
+type hookCall struct {
+	hooks  []Hook
+	entity Entity
+	old    any
+	new    any
+}
+
+// OnAdd registers a hook that runs when a component of the type is added to an entity, by AddComponentToEntity,
+// SpawnBatch, ComponentStore.Set, AddTag and the like. Hooks cannot be registered on relation types
+func (m *Manager) OnAdd(componentType string, hook Hook) error {
+	hooks, err := m.hooks(componentType)
+	if err != nil {
+		return err
+	}
+	hooks.onAdd = append(hooks.onAdd, hook)
+	return nil
+}
+
+// OnSet registers a hook that runs whenever the data of a component of the type is stored, both when it is added,
+// after the OnAdd hooks, and when it replaces the data of the component the entity already has
+func (m *Manager) OnSet(componentType string, hook Hook) error {
+	hooks, err := m.hooks(componentType)
+	if err != nil {
+		return err
+	}
+	hooks.onSet = append(hooks.onSet, hook)
+	return nil
+}
+
+// OnRemove registers a hook that runs when a component of the type is removed from an entity, by
+// DeleteComponentOfEntity and the like, or when the entity is deleted, in which case the entity is no longer alive
+// when the hook runs
+func (m *Manager) OnRemove(componentType string, hook Hook) error {
+	hooks, err := m.hooks(componentType)
+	if err != nil {
+		return err
+	}
+	hooks.onRemove = append(hooks.onRemove, hook)
+	return nil
+}
+
+// hooks returns the hooks of the component type, creating them if it has none yet
+func (m *Manager) hooks(componentType string) (*componentHooks, error) {
+	info, ok := m.registry.byName(componentType)
+	if !ok {
+		return nil, ErrComponentTypeNotFound
+	}
+	if info.relation {
+		return nil, ErrRelationTypeMismatch
+	}
+	if info.hooks == nil {
+		info.hooks = &componentHooks{}
+	}
+	return info.hooks, nil
+}
+
+// setComponent stores the data as the component of the entity, adding the component if the entity does not have it,
+// marks it as added or changed and queues the hooks of the type. The data must have been checked
+func (m *Manager) setComponent(entity Entity, info *componentInfo, data any) {
+	c, row, existed := m.componentRow(entity, info)
+	var old any
+	if existed && info.hooks != nil && len(info.hooks.onSet) > 0 {
+		old = componentData(info, c, row)
+	}
+	if existed {
+		c.markChanged(row, m.changeTick)
+	} else {
+		c, row = m.insertComponent(entity, info)
+	}
+	_ = c.set(row, data)
+	m.queueSetHooks(entity, info, existed, old, data)
+}
+
+// queueSetHooks queues the OnAdd hooks of the type if the component was added, and its OnSet hooks
+func (m *Manager) queueSetHooks(entity Entity, info *componentInfo, existed bool, old any, new any) {
+	if info.hooks == nil {
+		return
+	}
+	if !existed {
+		m.queueHooks(info.hooks.onAdd, entity, nil, new)
+	}
+	m.queueHooks(info.hooks.onSet, entity, old, new)
+}
+
+func (m *Manager) queueHooks(hooks []Hook, entity Entity, old any, new any) {
+	if len(hooks) == 0 {
+		return
+	}
+	m.hookQueue = append(m.hookQueue, hookCall{hooks: hooks, entity: entity, old: old, new: new})
+}
+
+// deferHooks calls fn and runs the hooks it queues once it returns, rather than at the end of every public method it
+// calls
+func (m *Manager) deferHooks(fn func()) {
+	running := m.runningHooks
+	m.runningHooks = true
+	func() {
+		defer func() { m.runningHooks = running }()
+		fn()
+	}()
+	m.runHooks()
+}
+
+// runHooks runs the queued hooks, including the ones they queue themselves. Every public method that queues hooks
+// calls it before returning, it does nothing when called from within a hook
+func (m *Manager) runHooks() {
+	if m.runningHooks {
+		return
+	}
+	m.runningHooks = true
+	defer func() {
+		clear(m.hookQueue)
+		m.hookQueue = m.hookQueue[:0]
+		m.runningHooks = false
+	}()
+
+	for i := 0; i < len(m.hookQueue); i++ {
+		call := m.hookQueue[i]
+		for _, hook := range call.hooks {
+			hook(m, call.entity, call.old, call.new)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testHookCall struct {
+	hook   string
+	entity Entity
+	old    any
+	new    any
+}
+
+// recordHooks registers hooks on the component type that append their calls to the returned slice
+func recordHooks(t *testing.T, m *Manager, componentType string) *[]testHookCall {
+	t.Helper()
+	calls := make([]testHookCall, 0)
+	record := func(name string) Hook {
+		return func(_ *Manager, entity Entity, old any, new any) {
+			calls = append(calls, testHookCall{hook: name, entity: entity, old: old, new: new})
+		}
+	}
+	require.NoError(t, m.OnAdd(componentType, record("add")))
+	require.NoError(t, m.OnSet(componentType, record("set")))
+	require.NoError(t, m.OnRemove(componentType, record("remove")))
+	return &calls
+}
+
+func Test_Hooks(t *testing.T) {
+	t.Log("Add, set and remove component - runs hooks")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		calls := recordHooks(t, m, "Health")
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 100}))
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 50}))
+		require.NoError(t, m.DeleteComponentOfEntity(e, "Health"))
+		require.Equal(t, []testHookCall{
+			{hook: "add", entity: e, new: 100},
+			{hook: "set", entity: e, new: 100},
+			{hook: "set", entity: e, old: 100, new: 50},
+			{hook: "remove", entity: e, old: 50},
+		}, *calls)
+	}
+
+	t.Log("Delete entity - runs remove hooks of all components")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		require.NoError(t, m.RegisterComponentType("Handle", SparseSetStorage))
+		health := recordHooks(t, m, "Health")
+		handle := recordHooks(t, m, "Handle")
+		entities, err := m.SpawnBatch(2, Component{Type: "Health", Data: 100}, Component{Type: "Handle", Data: "file"})
+		require.NoError(t, err)
+		require.Len(t, *health, 4)
+		require.Len(t, *handle, 4)
+
+		*health = (*health)[:0]
+		*handle = (*handle)[:0]
+		var alive bool
+		require.NoError(t, m.OnRemove("Handle", func(m *Manager, entity Entity, _ any, _ any) {
+			alive = m.IsAlive(entity)
+		}))
+		require.NoError(t, m.DeleteEntities(entities))
+		require.Equal(t, []testHookCall{
+			{hook: "remove", entity: entities[0], old: 100},
+			{hook: "remove", entity: entities[1], old: 100},
+		}, *health)
+		require.Equal(t, []testHookCall{
+			{hook: "remove", entity: entities[0], old: "file"},
+			{hook: "remove", entity: entities[1], old: "file"},
+		}, *handle)
+		require.False(t, alive)
+	}
+
+	t.Log("Typed stores and tags - run hooks")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		require.NoError(t, m.RegisterTag("Frozen", TableStorage))
+		calls := recordHooks(t, m, positions.Name())
+		tags := recordHooks(t, m, "Frozen")
+		e := m.CreateEntity()
+		require.NoError(t, positions.Set(e, testPosition{X: 1}))
+		require.NoError(t, positions.Remove(e))
+		require.NoError(t, m.AddTag(e, "Frozen"))
+		require.NoError(t, m.AddTag(e, "Frozen"))
+		require.NoError(t, m.RemoveTag(e, "Frozen"))
+		require.Equal(t, []testHookCall{
+			{hook: "add", entity: e, new: testPosition{X: 1}},
+			{hook: "set", entity: e, new: testPosition{X: 1}},
+			{hook: "remove", entity: e, old: testPosition{X: 1}},
+		}, *calls)
+		require.Equal(t, []testHookCall{
+			{hook: "add", entity: e, new: struct{}{}},
+			{hook: "set", entity: e, new: struct{}{}},
+			{hook: "remove", entity: e, old: struct{}{}},
+		}, *tags)
+	}
+
+	t.Log("Register hook on unknown or relation type - fails")
+	{
+		m := NewManager()
+		require.ErrorIs(t, m.OnAdd("Unknown", nil), ErrComponentTypeNotFound)
+		require.NoError(t, m.RegisterRelation("Likes"))
+		require.ErrorIs(t, m.OnSet("Likes", nil), ErrRelationTypeMismatch)
+	}
+}
+
+func Test_HooksMutatingWorld(t *testing.T) {
+	t.Log("Hook changing the world - nested hooks run after it returns")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		require.NoError(t, m.RegisterComponentType("Dead", TableStorage))
+		order := make([]string, 0)
+		require.NoError(t, m.OnSet("Health", func(m *Manager, entity Entity, _ any, new any) {
+			order = append(order, "health set")
+			if new.(int) <= 0 {
+				require.NoError(t, m.AddComponentToEntity(entity, Component{Type: "Dead", Data: true}))
+				order = append(order, "health set done")
+			}
+		}))
+		require.NoError(t, m.OnAdd("Dead", func(m *Manager, entity Entity, _ any, _ any) {
+			order = append(order, "dead added")
+			require.NoError(t, m.DeleteEntity(entity))
+		}))
+		require.NoError(t, m.OnRemove("Health", func(m *Manager, entity Entity, _ any, _ any) {
+			order = append(order, "health removed")
+		}))
+
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 0}))
+		require.Equal(t, []string{"health set", "health set done", "dead added", "health removed"}, order)
+		require.False(t, m.IsAlive(e))
+		require.Empty(t, m.hookQueue)
+	}
+
+	t.Log("Hook deleting an entity being deleted in a batch - fails without corrupting the batch")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		entities, err := m.SpawnBatch(3, Component{Type: "Health", Data: 1})
+		require.NoError(t, err)
+		errs := make([]error, 0)
+		require.NoError(t, m.OnRemove("Health", func(m *Manager, _ Entity, _ any, _ any) {
+			errs = append(errs, m.DeleteEntity(entities[2]))
+		}))
+		require.NoError(t, m.DeleteEntities(entities))
+		require.Equal(t, 0, m.EntityCount())
+		require.Len(t, errs, 3)
+		for _, err := range errs {
+			require.ErrorIs(t, err, ErrStaleEntity)
+		}
+	}
+
+	t.Log("Hook panicking - does not leave hooks queued")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Health", TableStorage))
+		require.NoError(t, m.OnAdd("Health", func(*Manager, Entity, any, any) { panic("hook failed") }))
+		e := m.CreateEntity()
+		require.Panics(t, func() { _ = m.AddComponentToEntity(e, Component{Type: "Health", Data: 1}) })
+		require.False(t, m.runningHooks)
+		require.Empty(t, m.hookQueue)
+	}
+}
@@ -0,0 +1,86 @@
+package ecs
+
+import (
+	"iter"
+)
+
+// Row gives access to the components of an entity matched by a query, by their index in Query.Types. A Row is only
+// valid until the iteration that yielded it moves on
+type Row struct {
+	q         *Query
+	archetype *archetype
+	row       int
+	entity    Entity
+}
+
+// All returns an iterator over the entities matching the query and their rows in the order of the query, see Order. It
+// streams the matches without copying any component. Components must not be added or removed while iterating, record
+// such changes in a CommandBuffer and apply it afterwards
+func (q *Query) All() iter.Seq2[Entity, Row] {
+	return func(yield func(Entity, Row) bool) {
+		q.each(func(a *archetype, row int, entity Entity) bool {
+			return yield(entity, Row{q: q, archetype: a, row: row, entity: entity})
+		})
+	}
+}
+
+// Entity returns the entity of the row
+func (r Row) Entity() Entity {
+	return r.entity
+}
+
+// Has returns true if the entity has the component of the i-th fetched type, which is always the case for With types
+func (r Row) Has(i int) bool {
+	_, _, ok := r.column(i)
+	return ok
+}
+
+// Get returns the data of the component of the i-th fetched type, or false if the entity does not have it. The data of
+// component types registered with Register is copied into an interface value, use RowData to read it without
+// allocating
+func (r Row) Get(i int) (any, bool) {
+	c, row, ok := r.column(i)
+	if !ok {
+		return nil, false
+	}
+	return componentData(r.q.infos[i], c, row), true
+}
+
+// column returns the column and row holding the component of the i-th fetched type, or false if the entity does not
+// have it
+func (r Row) column(i int) (column, int, bool) {
+	info := r.q.infos[i]
+	if info == nil {
+		return nil, 0, false
+	}
+	if info.set != nil {
+		row := info.set.dense(r.entity)
+		return info.set.column, row, row >= 0
+	}
+	j := r.archetype.column(info.ID)
+	if j < 0 {
+		return nil, 0, false
+	}
+	return r.archetype.columns[j], r.row, true
+}
+
+// RowData returns the data of the component of the i-th fetched type of the row. It returns ErrComponentNotFound if the
+// entity does not have the component, and ErrComponentDataMismatch if the data is not of type T
+func RowData[T any](r Row, i int) (T, error) {
+	c, row, ok := r.column(i)
+	if !ok {
+		var zero T
+		return zero, ErrComponentNotFound
+	}
+	if r.q.infos[i].relation {
+		return castData[T](componentData(r.q.infos[i], c, row))
+	}
+	if typed, ok := c.(*typedColumn[T]); ok {
+		return typed.data[row], nil
+	}
+	if untyped, ok := c.(*typedColumn[any]); ok {
+		return castData[T](untyped.data[row])
+	}
+	var zero T
+	return zero, ErrComponentDataMismatch
+}
@@ -0,0 +1,89 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QueryAll(t *testing.T) {
+	t.Log("Iterate query - yields the same matches as Execute")
+	{
+		m, _ := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").Optional("Mass").AnyOf("Circle", "Box").Build()
+		require.NoError(t, err)
+		expected := q.Execute()
+		for _, register := range []bool{false, true} {
+			if register {
+				q.Register()
+			}
+			count := 0
+			for entity, row := range q.All() {
+				count++
+				require.Equal(t, entity, row.Entity())
+				require.Contains(t, expected, entity)
+				for i, c := range expected[entity] {
+					data, ok := row.Get(i)
+					require.Equal(t, c != nil, ok)
+					require.Equal(t, c != nil, row.Has(i))
+					if c != nil {
+						require.Equal(t, c.Data, data)
+					}
+				}
+			}
+			require.Equal(t, len(expected), count)
+		}
+	}
+
+	t.Log("Read row data - succeeds")
+	{
+		m, entities := newTestQueryManager(t)
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		require.NoError(t, positions.Set(entities[1], testPosition{X: 1, Y: 2}))
+		q, err := m.NewQuery().With("Mass").Optional(positions.Name(), "Box").Build()
+		require.NoError(t, err)
+		for entity, row := range q.All() {
+			require.Equal(t, entities[1], entity)
+			mass, err := RowData[int](row, 0)
+			require.NoError(t, err)
+			require.Equal(t, 10, mass)
+			position, err := RowData[testPosition](row, 1)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 1, Y: 2}, position)
+		}
+	}
+
+	t.Log("Read row data - fails")
+	{
+		m, _ := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Mass").Optional("Box").Build()
+		require.NoError(t, err)
+		for _, row := range q.All() {
+			_, err := RowData[string](row, 0)
+			require.ErrorIs(t, err, ErrComponentDataMismatch)
+			_, err = RowData[float64](row, 1)
+			require.ErrorIs(t, err, ErrComponentNotFound)
+		}
+	}
+
+	t.Log("Break out of iteration - stops early")
+	{
+		m, _ := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").Build()
+		require.NoError(t, err)
+		for _, register := range []bool{false, true} {
+			if register {
+				q.Register()
+			}
+			count := 0
+			for range q.All() {
+				count++
+				if count == 2 {
+					break
+				}
+			}
+			require.Equal(t, 2, count)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ecs
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrDuplicateName = errors.New("name is already used by another entity")
+var ErrInvalidName = errors.New("name must not contain the path separator")
+
+// PathSeparator separates the names of an entity and its ancestors in the paths accepted by Lookup
+const PathSeparator = "/"
+
+// SetName gives the entity a name that is unique within the Manager, replacing its previous name. An empty name removes
+// the name of the entity. It returns ErrDuplicateName if another entity has the name, and ErrInvalidName if the name
+// contains PathSeparator
+func (m *Manager) SetName(entity Entity, name string) error {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	if strings.Contains(name, PathSeparator) {
+		return ErrInvalidName
+	}
+	if owner, ok := m.names[name]; ok && owner != entity {
+		return ErrDuplicateName
+	}
+
+	m.releaseName(entity)
+	if name != "" {
+		m.entities[entity.Index()].name = name
+		m.names[name] = entity
+	}
+	return nil
+}
+
+// Name returns the name of the entity, or false if the entity has no name or is not alive
+func (m *Manager) Name(entity Entity) (string, bool) {
+	if m.checkEntity(entity) != nil {
+		return "", false
+	}
+	name := m.entities[entity.Index()].name
+	return name, name != ""
+}
+
+// Lookup returns the entity with the given name. The name can be a path such as "level1/boss/weapon", which names an
+// entity and the ancestors it has below the first name of the path, each name being the parent of the next. It returns
+// ErrEntityNotFound if no entity has the name or the named entities do not form the path
+func (m *Manager) Lookup(path string) (Entity, error) {
+	var entity Entity
+	for i, name := range strings.Split(path, PathSeparator) {
+		next, ok := m.names[name]
+		if !ok {
+			return 0, ErrEntityNotFound
+		}
+		if i > 0 {
+			if parent, ok := m.Parent(next); !ok || parent != entity {
+				return 0, ErrEntityNotFound
+			}
+		}
+		entity = next
+	}
+	return entity, nil
+}
+
+// releaseName removes the name of a live entity so that other entities can use it
+func (m *Manager) releaseName(entity Entity) {
+	record := &m.entities[entity.Index()]
+	if record.name == "" {
+		return
+	}
+	delete(m.names, record.name)
+	record.name = ""
+}
@@ -0,0 +1,102 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SetName(t *testing.T) {
+	t.Log("Set and replace name - succeeds")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		_, ok := m.Name(e)
+		require.False(t, ok)
+		require.NoError(t, m.SetName(e, "boss"))
+		require.NoError(t, m.SetName(e, "boss"))
+		name, ok := m.Name(e)
+		require.True(t, ok)
+		require.Equal(t, "boss", name)
+
+		require.NoError(t, m.SetName(e, "villain"))
+		_, err := m.Lookup("boss")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+		require.NoError(t, m.SetName(e, ""))
+		_, ok = m.Name(e)
+		require.False(t, ok)
+		_, err = m.Lookup("villain")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+	}
+
+	t.Log("Set duplicate or invalid name - fails")
+	{
+		m := NewManager()
+		e1 := m.CreateEntity()
+		e2 := m.CreateEntity()
+		require.NoError(t, m.SetName(e1, "boss"))
+		require.ErrorIs(t, m.SetName(e2, "boss"), ErrDuplicateName)
+		require.ErrorIs(t, m.SetName(e2, "level1/boss"), ErrInvalidName)
+		_, ok := m.Name(e2)
+		require.False(t, ok)
+	}
+
+	t.Log("Delete named entity - releases the name")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.NoError(t, m.SetName(e, "boss"))
+		require.NoError(t, m.DeleteEntity(e))
+		_, err := m.Lookup("boss")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+
+		reused := m.CreateEntity()
+		require.Equal(t, e.Index(), reused.Index())
+		_, ok := m.Name(reused)
+		require.False(t, ok)
+		require.NoError(t, m.SetName(m.CreateEntity(), "boss"))
+	}
+}
+
+func Test_Lookup(t *testing.T) {
+	t.Log("Lookup name and path - succeeds")
+	{
+		m := NewManager()
+		level := m.CreateEntity()
+		boss := m.CreateEntity()
+		weapon := m.CreateEntity()
+		require.NoError(t, m.SetParent(boss, level))
+		require.NoError(t, m.SetParent(weapon, boss))
+		require.NoError(t, m.SetName(level, "level1"))
+		require.NoError(t, m.SetName(boss, "boss"))
+		require.NoError(t, m.SetName(weapon, "weapon"))
+
+		for path, expected := range map[string]Entity{
+			"level1":             level,
+			"weapon":             weapon,
+			"boss/weapon":        weapon,
+			"level1/boss/weapon": weapon,
+		} {
+			e, err := m.Lookup(path)
+			require.NoError(t, err)
+			require.Equal(t, expected, e, path)
+		}
+	}
+
+	t.Log("Lookup path that does not match the hierarchy - fails")
+	{
+		m := NewManager()
+		level := m.CreateEntity()
+		boss := m.CreateEntity()
+		require.NoError(t, m.SetName(level, "level1"))
+		require.NoError(t, m.SetName(boss, "boss"))
+		_, err := m.Lookup("level1/boss")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+		_, err = m.Lookup("boss/level1")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+		_, err = m.Lookup("")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+		_, err = m.Lookup("level1/")
+		require.ErrorIs(t, err, ErrEntityNotFound)
+	}
+}
@@ -0,0 +1,63 @@
+package ecs
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Order is the order in which a query visits the entities it matches
+type Order int
+
+const (
+	// EntityOrder visits the entities by ascending index, which does not depend on how they were changed. It is the
+	// default. Matches are sorted on every iteration, which costs O(n log n) for n matches
+	EntityOrder Order = iota
+	// StorageOrder visits the entities in the order they are stored: archetype by archetype in order of creation, and
+	// within an archetype by row, or in the order of the sparse set of the rarest required type when the query requires
+	// a type in sparse set storage. Registered queries with types in sparse set storage visit the entities in the
+	// order they started matching instead. Deleting an entity, or moving it to another archetype, moves the last
+	// entity of its archetype or set into its place, so the order changes as entities change. It is the same for two
+	// Managers that went through the same calls and costs nothing to keep, for systems whose results do not depend on
+	// the order of the entities
+	StorageOrder
+)
+
+// OrderBy sets the order in which the query visits the entities it matches, the default is EntityOrder
+func (b *QueryBuilder) OrderBy(order Order) *QueryBuilder {
+	b.order = order
+	return b
+}
+
+// queryMatch is an entity matching a query, along with the archetype and row holding its table components
+type queryMatch struct {
+	archetype *archetype
+	row       int
+	entity    Entity
+}
+
+// each calls fn with every entity matching the query in the order of the query, along with the archetype and row
+// holding its table components, until fn returns false
+func (q *Query) each(fn func(a *archetype, row int, entity Entity) bool) {
+	q.refresh()
+	if q.order != EntityOrder {
+		q.eachStored(fn)
+		return
+	}
+
+	// An iteration nested in fn finds no buffer and allocates its own
+	sorted := q.sorted[:0]
+	q.sorted = nil
+	q.eachStored(func(a *archetype, row int, entity Entity) bool {
+		sorted = append(sorted, queryMatch{archetype: a, row: row, entity: entity})
+		return true
+	})
+	slices.SortFunc(sorted, func(x, y queryMatch) int {
+		return cmp.Compare(x.entity.Index(), y.entity.Index())
+	})
+	for _, match := range sorted {
+		if !fn(match.archetype, match.row, match.entity) {
+			break
+		}
+	}
+	q.sorted = sorted
+}
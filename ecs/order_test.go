@@ -0,0 +1,171 @@
+package ecs
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runTestSimulation churns a world with random changes from the seed, while a system with order dependent side effects
+// iterates over the query, and returns the trace of the entities the system visited
+func runTestSimulation(t *testing.T, seed uint64, order Order, register bool) []Entity {
+	t.Helper()
+	m := NewManager()
+	require.NoError(t, m.RegisterComponentType("A", TableStorage))
+	require.NoError(t, m.RegisterComponentType("B", TableStorage))
+	require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+	relations := []string{"R0", "R1", "R2", "R3"}
+	for _, relation := range relations {
+		require.NoError(t, m.RegisterRelation(relation))
+	}
+	q, err := NewQuery1[int](m.NewQuery().AnyOf("B", "Sparse").Without(relations...).OrderBy(order), Mutable("A"))
+	require.NoError(t, err)
+	if register {
+		q.Query().Register()
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed))
+	types := []string{"A", "B", "Sparse"}
+	entities := make([]Entity, 0)
+	trace := make([]Entity, 0)
+	for step := 0; step < 300; step++ {
+		for range 5 {
+			switch op := rng.IntN(6); {
+			case op == 0 || len(entities) == 0:
+				entity, err := m.SpawnBatch(1, Component{Type: "A", Data: step}, Component{Type: types[1+rng.IntN(2)], Data: step})
+				require.NoError(t, err)
+				entities = append(entities, entity...)
+			case op == 1:
+				i := rng.IntN(len(entities))
+				require.NoError(t, m.DeleteEntity(entities[i]))
+				entities = slices.Delete(entities, i, i+1)
+			case op < 4:
+				// Sources of relations to a deleted target stop being excluded when it is deleted
+				source, target := entities[rng.IntN(len(entities))], entities[rng.IntN(len(entities))]
+				if source != target {
+					require.NoError(t, m.AddRelation(source, relations[rng.IntN(len(relations))], target))
+				}
+			case op == 4:
+				require.NoError(t, m.AddComponentToEntity(entities[rng.IntN(len(entities))], Component{Type: types[rng.IntN(3)], Data: step}))
+			default:
+				_ = m.DeleteComponentOfEntity(entities[rng.IntN(len(entities))], types[rng.IntN(3)])
+			}
+		}
+
+		// Every entity takes the value of the one visited before it, and every tenth one visited is despawned
+		commands := NewCommandBuffer(m)
+		previous := step
+		require.NoError(t, q.Each(func(entity Entity, a *int) bool {
+			trace = append(trace, entity)
+			*a, previous = previous, *a
+			if len(trace)%10 == 0 {
+				commands.Despawn(entity)
+			}
+			return true
+		}))
+		require.NoError(t, commands.Apply())
+		entities = slices.DeleteFunc(entities, func(entity Entity) bool { return !m.IsAlive(entity) })
+		trace = append(trace, Entity(previous))
+	}
+	return trace
+}
+
+func Test_QueryOrder(t *testing.T) {
+	t.Log("Run identical simulations - produces identical traces")
+	{
+		for _, order := range []Order{StorageOrder, EntityOrder} {
+			for _, register := range []bool{false, true} {
+				trace := runTestSimulation(t, 7, order, register)
+				require.Greater(t, len(trace), 500)
+				require.Equal(t, trace, runTestSimulation(t, 7, order, register))
+				require.NotEqual(t, trace, runTestSimulation(t, 8, order, register))
+			}
+		}
+	}
+
+	t.Log("Delete the target of relations of several types - gives the same order on every run")
+	{
+		run := func() []Entity {
+			m := NewManager()
+			require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+			relations := make([]string, 8)
+			for i := range relations {
+				relations[i] = fmt.Sprintf("R%d", i)
+				require.NoError(t, m.RegisterRelation(relations[i]))
+			}
+			q, err := m.NewQuery().With("Sparse").Without(relations...).Build()
+			require.NoError(t, err)
+			q.Register()
+
+			target := m.CreateEntity()
+			for _, relation := range relations {
+				source := m.CreateEntity()
+				require.NoError(t, m.AddComponentToEntity(source, Component{Type: "Sparse"}))
+				require.NoError(t, m.AddRelation(source, relation, target))
+			}
+			require.NoError(t, m.DeleteEntity(target))
+			visited := make([]Entity, 0)
+			for entity := range q.All() {
+				visited = append(visited, entity)
+			}
+			return visited
+		}
+		expected := run()
+		require.Len(t, expected, 8)
+		for range 20 {
+			require.Equal(t, expected, run())
+		}
+	}
+
+	t.Log("Iterate in entity order - visits entities by ascending index")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+		entities, err := m.SpawnBatch(20, Component{Type: "A", Data: 0})
+		require.NoError(t, err)
+		for i := len(entities) - 1; i >= 0; i -= 3 {
+			require.NoError(t, m.AddComponentToEntity(entities[i], Component{Type: "B", Data: i}))
+			require.NoError(t, m.AddComponentToEntity(entities[i], Component{Type: "Sparse", Data: i}))
+		}
+		require.NoError(t, m.DeleteEntity(entities[4]))
+
+		for _, types := range [][]string{{"A"}, {"Sparse"}, {"A", "B"}} {
+			// EntityOrder is the default
+			q, err := m.NewQuery().With(types...).Build()
+			require.NoError(t, err)
+			for _, register := range []bool{false, true} {
+				if register {
+					q.Register()
+				}
+				visited := make([]Entity, 0)
+				for entity := range q.All() {
+					visited = append(visited, entity)
+				}
+				require.Len(t, visited, len(q.Execute()))
+				require.True(t, slices.IsSortedFunc(visited, func(x, y Entity) int { return int(x.Index()) - int(y.Index()) }))
+			}
+		}
+	}
+
+	t.Log("Nest iterations in entity order - succeeds")
+	{
+		m := NewManager()
+		_, err := m.SpawnBatch(3, Component{Type: "A", Data: 0})
+		require.NoError(t, err)
+		q, err := m.NewQuery().With("A").OrderBy(EntityOrder).Build()
+		require.NoError(t, err)
+		pairs := make([][2]Entity, 0)
+		for outer := range q.All() {
+			for inner := range q.All() {
+				if inner == outer {
+					break
+				}
+				pairs = append(pairs, [2]Entity{outer, inner})
+			}
+		}
+		require.Equal(t, [][2]Entity{{1, 0}, {2, 0}, {2, 1}}, pairs)
+	}
+}
@@ -0,0 +1,222 @@
+package ecs
+
+import (
+	"errors"
+	"reflect"
+)
+
+var ErrPrefabCycle = errors.New("prefab inherits from itself")
+var ErrCloneTypeMismatch = errors.New("clone is not of the type of the cloned data")
+
+// Cloner is implemented by component data that copies itself when an entity is instantiated from a prefab, for
+// example to copy state held in unexported fields. Clone must return a value of the same Go type
+type Cloner interface {
+	Clone() any
+}
+
+// Prefab is a named template of default components that entities are instantiated from
+type Prefab struct {
+	Name string
+	// Base is the prefab this one inherits from, its components are used unless this prefab has a component of the
+	// same type
+	Base       *Prefab
+	Components []Component
+}
+
+// NewPrefab returns a prefab with the given default components
+func NewPrefab(name string, components ...Component) *Prefab {
+	return &Prefab{
+		Name:       name,
+		Components: components,
+	}
+}
+
+// Extend returns a prefab that inherits the components of p, replacing or adding the given components
+func (p *Prefab) Extend(name string, components ...Component) *Prefab {
+	return &Prefab{
+		Name:       name,
+		Base:       p,
+		Components: components,
+	}
+}
+
+// Resolve returns the components of the prefab merged with the components it inherits. Components of a base prefab
+// come first, in the order the base declares them, and a component keeps the position of the first prefab that
+// declared its type. It returns ErrPrefabCycle if the prefab inherits from itself
+func (p *Prefab) Resolve() ([]Component, error) {
+	chain := make([]*Prefab, 0)
+	seen := make(map[*Prefab]bool)
+	for prefab := p; prefab != nil; prefab = prefab.Base {
+		if seen[prefab] {
+			return nil, ErrPrefabCycle
+		}
+		seen[prefab] = true
+		chain = append(chain, prefab)
+	}
+
+	components := make([]Component, 0)
+	for i := len(chain) - 1; i >= 0; i-- {
+		components = mergeComponents(components, chain[i].Components)
+	}
+	return components, nil
+}
+
+// mergeComponents replaces the components of the same type as the overrides in place, and appends the others
+func mergeComponents(components []Component, overrides []Component) []Component {
+	positions := make(map[string]int, len(components))
+	for i, c := range components {
+		positions[c.Type] = i
+	}
+	for _, c := range overrides {
+		if i, ok := positions[c.Type]; ok {
+			components[i] = c
+			continue
+		}
+		positions[c.Type] = len(components)
+		components = append(components, c)
+	}
+	return components
+}
+
+// Instantiate creates an entity with the resolved components of the prefab, replaced or extended by the overrides.
+// The data of the prefab components is deep-copied, see deepCopy, overrides are used as they are. If any component is
+// rejected no entity is created, and ErrCloneTypeMismatch is returned if a Cloner returns a value of another type
+func (m *Manager) Instantiate(prefab *Prefab, overrides ...Component) (Entity, error) {
+	components, err := prefab.Resolve()
+	if err != nil {
+		return 0, err
+	}
+	for i := range components {
+		if components[i].Data, err = deepCopy(components[i].Data); err != nil {
+			return 0, err
+		}
+	}
+
+	entities, err := m.SpawnBatch(1, mergeComponents(components, overrides)...)
+	if err != nil {
+		return 0, err
+	}
+	return entities[0], nil
+}
+
+// deepCopy returns a copy of the data that does not share the pointers, slices, maps and interfaces reached through
+// exported struct fields with it. Values implementing Cloner are copied by calling Clone instead. Unexported fields
+// are copied as they are, like an assignment does, so the state they point to is shared unless their type implements
+// Cloner, and so are channels and functions
+func deepCopy(data any) (any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	c, err := copyValue(reflect.ValueOf(data), make(map[copiedPointer]reflect.Value))
+	if err != nil {
+		return nil, err
+	}
+	return c.Interface(), nil
+}
+
+// copiedPointer identifies a pointer copied by copyValue. A pointer to a struct and a pointer to its first field have
+// the same address, so the type is part of the key
+type copiedPointer struct {
+	addr uintptr
+	t    reflect.Type
+}
+
+var clonerType = reflect.TypeFor[Cloner]()
+
+// copyValue deep-copies the value, copies tracks the pointers already copied so shared and cyclic pointers stay so
+func copyValue(v reflect.Value, copies map[copiedPointer]reflect.Value) (reflect.Value, error) {
+	if v.Type().Implements(clonerType) && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		c := reflect.ValueOf(v.Interface().(Cloner).Clone())
+		if !c.IsValid() || !c.Type().AssignableTo(v.Type()) {
+			return reflect.Value{}, ErrCloneTypeMismatch
+		}
+		return c, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, nil
+		}
+		key := copiedPointer{addr: v.Pointer(), t: v.Type()}
+		if c, ok := copies[key]; ok {
+			return c, nil
+		}
+		c := reflect.New(v.Type().Elem())
+		copies[key] = c
+		elem, err := copyValue(v.Elem(), copies)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		c.Elem().Set(elem)
+		return c, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		return c, copyElements(c, v, copies)
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		return c, copyElements(c, v, copies)
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := copyValue(iter.Key(), copies)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			value, err := copyValue(iter.Value(), copies)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			c.SetMapIndex(key, value)
+		}
+		return c, nil
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			// Unexported fields keep the value copied above
+			field := c.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			value, err := copyValue(field, copies)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			field.Set(value)
+		}
+		return c, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := copyValue(v.Elem(), copies)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(elem)
+		return c, nil
+	default:
+		return v, nil
+	}
+}
+
+// copyElements deep-copies the elements of the slice or array v into c, which has the same length
+func copyElements(c reflect.Value, v reflect.Value, copies map[copiedPointer]reflect.Value) error {
+	for i := 0; i < v.Len(); i++ {
+		elem, err := copyValue(v.Index(i), copies)
+		if err != nil {
+			return err
+		}
+		c.Index(i).Set(elem)
+	}
+	return nil
+}
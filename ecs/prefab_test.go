@@ -0,0 +1,221 @@
+package ecs
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testInventory struct {
+	Items  []string
+	Counts map[string]int
+	Owner  *testPosition
+}
+
+func Test_PrefabResolve(t *testing.T) {
+	t.Log("Resolve prefab inheriting from a base - succeeds")
+	{
+		base := NewPrefab("Enemy",
+			Component{Type: "Health", Data: 100},
+			Component{Type: "Speed", Data: 1.0},
+		)
+		boss := base.Extend("Boss",
+			Component{Type: "Health", Data: 500},
+			Component{Type: "Name", Data: "Boss"},
+		)
+		components, err := boss.Resolve()
+		require.NoError(t, err)
+		require.Equal(t, []Component{
+			{Type: "Health", Data: 500},
+			{Type: "Speed", Data: 1.0},
+			{Type: "Name", Data: "Boss"},
+		}, components)
+
+		components, err = base.Resolve()
+		require.NoError(t, err)
+		require.Equal(t, []Component{
+			{Type: "Health", Data: 100},
+			{Type: "Speed", Data: 1.0},
+		}, components)
+	}
+
+	t.Log("Resolve prefab inheriting from itself - fails")
+	{
+		base := NewPrefab("Base")
+		derived := base.Extend("Derived")
+		base.Base = derived
+		_, err := derived.Resolve()
+		require.ErrorIs(t, err, ErrPrefabCycle)
+	}
+}
+
+func Test_Instantiate(t *testing.T) {
+	t.Log("Instantiate prefab with overrides - succeeds")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		prefab := NewPrefab("Enemy",
+			Component{Type: positions.Name(), Data: testPosition{X: 1, Y: 2}},
+			Component{Type: "Health", Data: 100},
+		).Extend("Boss", Component{Type: "Health", Data: 500})
+
+		entity, err := m.Instantiate(prefab, Component{Type: positions.Name(), Data: testPosition{X: 5, Y: 6}})
+		require.NoError(t, err)
+		p, err := positions.Get(entity)
+		require.NoError(t, err)
+		require.Equal(t, testPosition{X: 5, Y: 6}, *p)
+		health, err := m.GetComponentOfEntity(entity, "Health")
+		require.NoError(t, err)
+		require.Equal(t, 500, health.Data)
+	}
+
+	t.Log("Instances do not share mutable state - succeeds")
+	{
+		m := NewManager()
+		inventories, err := Register[testInventory](m)
+		require.NoError(t, err)
+		prefab := NewPrefab("Chest", Component{Type: inventories.Name(), Data: testInventory{
+			Items:  []string{"sword"},
+			Counts: map[string]int{"sword": 1},
+			Owner:  &testPosition{X: 1},
+		}})
+
+		first, err := m.Instantiate(prefab)
+		require.NoError(t, err)
+		second, err := m.Instantiate(prefab)
+		require.NoError(t, err)
+
+		inventory, err := inventories.Get(first)
+		require.NoError(t, err)
+		inventory.Items[0] = "shield"
+		inventory.Counts["sword"] = 2
+		inventory.Owner.X = 2
+
+		inventory, err = inventories.Get(second)
+		require.NoError(t, err)
+		require.Equal(t, testInventory{
+			Items:  []string{"sword"},
+			Counts: map[string]int{"sword": 1},
+			Owner:  &testPosition{X: 1},
+		}, *inventory)
+		require.Equal(t, []string{"sword"}, prefab.Components[0].Data.(testInventory).Items)
+	}
+
+	t.Log("Instantiate prefab with mismatching data - creates nothing")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		prefab := NewPrefab("Enemy", Component{Type: positions.Name(), Data: "not a position"})
+		_, err = m.Instantiate(prefab)
+		require.ErrorIs(t, err, ErrComponentDataMismatch)
+		require.Equal(t, 0, m.EntityCount())
+	}
+}
+
+// testCloned holds state in unexported fields, which it copies itself
+type testCloned struct {
+	items []int
+}
+
+func (c testCloned) Clone() any {
+	return testCloned{items: slices.Clone(c.items)}
+}
+
+// testBadClone returns a clone of another type
+type testBadClone struct{}
+
+func (testBadClone) Clone() any {
+	return 1
+}
+
+// mustDeepCopy deep-copies the data and fails the test on errors
+func mustDeepCopy[T any](t *testing.T, data T) T {
+	t.Helper()
+	c, err := deepCopy(data)
+	require.NoError(t, err)
+	return c.(T)
+}
+
+func Test_DeepCopy(t *testing.T) {
+	t.Log("Deep copy keeps shared and cyclic pointers - succeeds")
+	{
+		type node struct {
+			Next *node
+		}
+		n := &node{}
+		n.Next = n
+		c := mustDeepCopy(t, n)
+		require.NotSame(t, n, c)
+		require.Same(t, c, c.Next)
+
+		shared := &testPosition{X: 1}
+		pair := mustDeepCopy(t, []*testPosition{shared, shared})
+		require.NotSame(t, shared, pair[0])
+		require.Same(t, pair[0], pair[1])
+	}
+
+	t.Log("Deep copy pointers to a struct and to its first field - succeeds")
+	{
+		type inner struct {
+			A int
+			B int
+		}
+		type aliased struct {
+			P *inner
+			Q *int
+		}
+		value := aliased{P: &inner{A: 1, B: 2}}
+		value.Q = &value.P.A
+		c := mustDeepCopy(t, value)
+		require.NotSame(t, value.P, c.P)
+		require.NotSame(t, value.Q, c.Q)
+		require.Equal(t, inner{A: 1, B: 2}, *c.P)
+		require.Equal(t, 1, *c.Q)
+	}
+
+	t.Log("Deep copy unexported fields - copies them as they are")
+	{
+		type hidden struct {
+			items []int
+			owner *testPosition
+		}
+		value := hidden{items: []int{1}, owner: &testPosition{X: 1}}
+		c := mustDeepCopy(t, value)
+		require.Same(t, value.owner, c.owner)
+		require.Equal(t, value.items, c.items)
+
+		now := time.Now()
+		require.Same(t, time.Local, mustDeepCopy(t, now).Location())
+
+		var mu sync.Mutex
+		type guarded struct {
+			Mu *sync.Mutex
+		}
+		require.True(t, mustDeepCopy(t, guarded{Mu: &mu}).Mu.TryLock())
+	}
+
+	t.Log("Deep copy values implementing Cloner - calls Clone")
+	{
+		value := struct{ C testCloned }{C: testCloned{items: []int{1}}}
+		c := mustDeepCopy(t, value)
+		c.C.items[0] = 2
+		require.Equal(t, []int{1}, value.C.items)
+
+		_, err := deepCopy(struct{ C testBadClone }{})
+		require.ErrorIs(t, err, ErrCloneTypeMismatch)
+	}
+
+	t.Log("Deep copy of nil and values - succeeds")
+	{
+		c, err := deepCopy(nil)
+		require.NoError(t, err)
+		require.Nil(t, c)
+		require.Equal(t, 42, mustDeepCopy(t, 42))
+		require.Equal(t, TestComponentString{content: "Hello"}, mustDeepCopy(t, TestComponentString{content: "Hello"}))
+	}
+}
@@ -0,0 +1,206 @@
+package ecs
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrContradictoryQuery = errors.New("query excludes a component type it requires or fetches as optional")
+var ErrEmptyAnyOf = errors.New("query AnyOf term has no component type that is not excluded")
+
+// QueryBuilder composes the terms of a Query
+type QueryBuilder struct {
+	m        *Manager
+	with     []string
+	without  []string
+	optional []string
+	anyOf    [][]string
+	order    Order
+}
+
+// NewQuery returns a builder for a query matching every entity until terms are added. The query visits the entities it
+// matches by ascending index unless another Order is set with OrderBy
+func (m *Manager) NewQuery() *QueryBuilder {
+	return &QueryBuilder{
+		m:        m,
+		with:     make([]string, 0),
+		without:  make([]string, 0),
+		optional: make([]string, 0),
+		anyOf:    make([][]string, 0),
+	}
+}
+
+// With requires matching entities to have all the given types, their components are fetched
+func (b *QueryBuilder) With(types ...string) *QueryBuilder {
+	b.with = append(b.with, types...)
+	return b
+}
+
+// Without requires matching entities to have none of the given types
+func (b *QueryBuilder) Without(types ...string) *QueryBuilder {
+	b.without = append(b.without, types...)
+	return b
+}
+
+// Optional fetches the components of the given types when matching entities have them, and nil otherwise
+func (b *QueryBuilder) Optional(types ...string) *QueryBuilder {
+	b.optional = append(b.optional, types...)
+	return b
+}
+
+// AnyOf requires matching entities to have at least one of the given types, their components are fetched when the
+// entities have them, and nil otherwise. Every call adds a separate term
+func (b *QueryBuilder) AnyOf(types ...string) *QueryBuilder {
+	b.anyOf = append(b.anyOf, slices.Clone(types))
+	return b
+}
+
+// Build compiles the query. The With and AnyOf types must be known to the Manager, otherwise ErrComponentTypeNotFound
+// is returned. Without and Optional types may not be known yet: until they are, Without types exclude nothing and
+// Optional types fetch nil. It returns ErrContradictoryQuery if a type is excluded and required or optional, and
+// ErrEmptyAnyOf if all types of an AnyOf term are excluded, since such queries can never match or fetch anything
+func (b *QueryBuilder) Build() (*Query, error) {
+	excluded := make(map[string]bool, len(b.without))
+	for _, t := range b.without {
+		excluded[t] = true
+	}
+	for _, t := range slices.Concat(b.with, b.optional) {
+		if excluded[t] {
+			return nil, ErrContradictoryQuery
+		}
+	}
+	for _, group := range b.anyOf {
+		if !slices.ContainsFunc(group, func(t string) bool { return !excluded[t] }) {
+			return nil, ErrEmptyAnyOf
+		}
+	}
+
+	q := &Query{
+		m: b.m,
+		builder: QueryBuilder{
+			m:        b.m,
+			with:     slices.Clone(b.with),
+			without:  slices.Clone(b.without),
+			optional: slices.Clone(b.optional),
+			anyOf:    slices.Clone(b.anyOf),
+		},
+		order: b.order,
+	}
+	if err := q.compile(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// compile resolves the terms of the builder of the query into its filter and fetched types
+func (q *Query) compile() error {
+	b := &q.builder
+	with, err := b.m.lookupComponentTypes(b.with)
+	if err != nil {
+		return err
+	}
+	anyOf := make([][]*componentInfo, len(b.anyOf))
+	for i, types := range b.anyOf {
+		if anyOf[i], err = b.m.lookupComponentTypes(types); err != nil {
+			return err
+		}
+	}
+	q.unknown = false
+	without := make([]*componentInfo, 0, len(b.without))
+	for _, t := range b.without {
+		info, ok := b.m.registry.byName(t)
+		if !ok {
+			q.unknown = true
+			continue
+		}
+		without = append(without, info)
+	}
+	// Optional types that are not known yet have no entry, their components are nil
+	optional := make([]*componentInfo, len(b.optional))
+	for i, t := range b.optional {
+		info, ok := b.m.registry.byName(t)
+		q.unknown = q.unknown || !ok
+		optional[i] = info
+	}
+
+	q.filter = b.m.newFilter(with, without)
+	for _, group := range anyOf {
+		b.m.addAnyOf(q.filter, group)
+	}
+	// Every type is fetched once, in order of the terms
+	q.types = make([]string, 0)
+	q.infos = make([]*componentInfo, 0)
+	names := append([][]string{b.with, b.optional}, b.anyOf...)
+	for i, term := range append([][]*componentInfo{with, optional}, anyOf...) {
+		for j, info := range term {
+			if t := names[i][j]; !slices.Contains(q.types, t) && !slices.Contains(b.without, t) {
+				q.types = append(q.types, t)
+				q.infos = append(q.infos, info)
+			}
+		}
+	}
+	q.compiled = len(b.m.registry.components)
+	return nil
+}
+
+// refresh compiles the query again if types it refers to have become known since it was compiled
+func (q *Query) refresh() {
+	if !q.unknown || q.compiled == len(q.m.registry.components) {
+		return
+	}
+	registered := q.IsRegistered()
+	q.Unregister()
+	// Types only become known, so the terms still compile
+	_ = q.compile()
+	if registered {
+		q.Register()
+	}
+}
+
+// Query is a compiled set of terms matching entities by their component types
+type Query struct {
+	m *Manager
+	// builder holds the terms the query is compiled from
+	builder QueryBuilder
+	filter  *filter
+	// types holds the names of the fetched types, the With types followed by the Optional and AnyOf types, and infos
+	// holds their registry entries, which are nil for Optional types that are not known yet
+	types []string
+	infos []*componentInfo
+	// compiled is the number of types known to the Manager when the query was compiled, unknown is true if some of its
+	// types were not known then
+	compiled int
+	unknown  bool
+	// cache holds the matches of the query while it is registered
+	cache *queryCache
+	order Order
+	// sorted is the buffer the matches are sorted in for EntityOrder, it is nil while an iteration uses it
+	sorted []queryMatch
+}
+
+// Types returns the fetched types in the order their components appear in the results: the With types followed by the
+// Optional and AnyOf types, each type appearing once
+func (q *Query) Types() []string {
+	return slices.Clone(q.types)
+}
+
+// Matches returns true if the entity is alive and matches the query
+func (q *Query) Matches(entity Entity) bool {
+	q.refresh()
+	return q.m.checkEntity(entity) == nil && q.filter.matches(q.m.entities[entity.Index()].signature)
+}
+
+// Execute returns the matching entities and their components in the order of Types, components the entity does not
+// have are nil. The components are copies, changes to them are only stored by passing them to AddComponentToEntity.
+// Being a map, the result has no order, use All to visit the matches in the order of the query
+func (q *Query) Execute() map[Entity][]*Component {
+	q.refresh()
+	result := make(map[Entity][]*Component)
+	rows := newComponentRows(q.infos)
+	// The result has no order, so the matches are not sorted
+	q.eachStored(func(a *archetype, row int, entity Entity) bool {
+		result[entity] = rows.fill(q.m, a, row, entity)
+		return true
+	})
+	return result
+}
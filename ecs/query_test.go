@@ -0,0 +1,150 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestQueryManager creates entities with Vector2 and Velocity2D, some of them Frozen, with Mass, Circle or Box
+func newTestQueryManager(t *testing.T) (*Manager, []Entity) {
+	t.Helper()
+	m := NewManager()
+	require.NoError(t, m.RegisterComponentType("Box", SparseSetStorage))
+	require.NoError(t, m.RegisterTag("Frozen", TableStorage))
+	entities, err := m.SpawnBatch(5,
+		Component{Type: "Vector2", Data: 0},
+		Component{Type: "Velocity2D", Data: 1},
+	)
+	require.NoError(t, err)
+	require.NoError(t, m.AddTag(entities[0], "Frozen"))
+	require.NoError(t, m.AddComponentToEntity(entities[1], Component{Type: "Mass", Data: 10}))
+	require.NoError(t, m.AddComponentToEntity(entities[1], Component{Type: "Circle", Data: 1.0}))
+	require.NoError(t, m.AddComponentToEntity(entities[2], Component{Type: "Box", Data: 2.0}))
+	require.NoError(t, m.AddComponentToEntity(entities[3], Component{Type: "Circle", Data: 3.0}))
+	require.NoError(t, m.AddComponentToEntity(entities[3], Component{Type: "Box", Data: 3.0}))
+	other := m.CreateEntity()
+	require.NoError(t, m.AddComponentToEntity(other, Component{Type: "Circle", Data: 4.0}))
+	return m, append(entities, other)
+}
+
+func Test_Query(t *testing.T) {
+	t.Log("Query with and without - succeeds")
+	{
+		m, entities := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2", "Velocity2D").Without("Frozen").Build()
+		require.NoError(t, err)
+		require.Equal(t, []string{"Vector2", "Velocity2D"}, q.Types())
+		result := q.Execute()
+		require.Len(t, result, 4)
+		require.NotContains(t, result, entities[0])
+		require.False(t, q.Matches(entities[0]))
+		require.True(t, q.Matches(entities[1]))
+	}
+
+	t.Log("Query optional components - are nil when missing")
+	{
+		m, entities := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").Optional("Mass").Build()
+		require.NoError(t, err)
+		result := q.Execute()
+		require.Len(t, result, 5)
+		require.Equal(t, 10, result[entities[1]][1].Data)
+		require.Nil(t, result[entities[2]][1])
+
+		mass, err := GetComponentData[int](result[entities[1]], "Mass")
+		require.NoError(t, err)
+		require.Equal(t, 10, *mass)
+		_, err = GetComponentData[int](result[entities[2]], "Mass")
+		require.ErrorIs(t, err, ErrComponentNotFound)
+	}
+
+	t.Log("Query any of table and sparse set types - succeeds")
+	{
+		m, entities := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").AnyOf("Circle", "Box").Build()
+		require.NoError(t, err)
+		require.Equal(t, []string{"Vector2", "Circle", "Box"}, q.Types())
+		result := q.Execute()
+		require.Len(t, result, 3)
+		require.Equal(t, 1.0, result[entities[1]][1].Data)
+		require.Nil(t, result[entities[1]][2])
+		require.Nil(t, result[entities[2]][1])
+		require.Equal(t, 2.0, result[entities[2]][2].Data)
+		require.Equal(t, 3.0, result[entities[3]][2].Data)
+
+		q, err = m.NewQuery().AnyOf("Circle", "Box").AnyOf("Box", "Mass").Without("Vector2").Build()
+		require.NoError(t, err)
+		require.Empty(t, q.Execute())
+		q, err = m.NewQuery().AnyOf("Circle").Build()
+		require.NoError(t, err)
+		require.Len(t, q.Execute(), 3)
+	}
+
+	t.Log("Query without terms - matches every entity")
+	{
+		m, _ := newTestQueryManager(t)
+		q, err := m.NewQuery().Build()
+		require.NoError(t, err)
+		require.Len(t, q.Execute(), 6)
+	}
+
+	t.Log("Query without types not known yet - excludes them once they are known")
+	{
+		m, entities := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").Without("Sleeping").Build()
+		require.NoError(t, err)
+		registered, err := m.NewQuery().With("Vector2").Without("Sleeping").Build()
+		require.NoError(t, err)
+		registered.Register()
+		require.Len(t, q.Execute(), 5)
+		require.Len(t, registered.Execute(), 5)
+
+		require.NoError(t, m.AddTag(entities[1], "Sleeping"))
+		for _, query := range []*Query{q, registered} {
+			result := query.Execute()
+			require.Len(t, result, 4)
+			require.NotContains(t, result, entities[1])
+			require.False(t, query.Matches(entities[1]))
+		}
+		require.True(t, registered.IsRegistered())
+	}
+
+	t.Log("Query optional types not known yet - fetches nil until they are known")
+	{
+		m, entities := newTestQueryManager(t)
+		q, err := m.NewQuery().With("Vector2").Optional("Health").Build()
+		require.NoError(t, err)
+		require.Equal(t, []string{"Vector2", "Health"}, q.Types())
+		result := q.Execute()
+		require.Len(t, result, 5)
+		require.Nil(t, result[entities[0]][1])
+		for _, row := range q.All() {
+			require.False(t, row.Has(1))
+		}
+
+		require.NoError(t, m.AddComponentToEntity(entities[0], Component{Type: "Health", Data: 3}))
+		result = q.Execute()
+		require.Equal(t, 3, result[entities[0]][1].Data)
+		require.Nil(t, result[entities[1]][1])
+	}
+
+	t.Log("Build contradictory queries - fails")
+	{
+		m, _ := newTestQueryManager(t)
+		_, err := m.NewQuery().With("Vector2").Without("Vector2").Build()
+		require.ErrorIs(t, err, ErrContradictoryQuery)
+		_, err = m.NewQuery().Optional("Mass").Without("Mass").Build()
+		require.ErrorIs(t, err, ErrContradictoryQuery)
+		_, err = m.NewQuery().AnyOf("Circle", "Box").Without("Box", "Circle").Build()
+		require.ErrorIs(t, err, ErrEmptyAnyOf)
+		_, err = m.NewQuery().AnyOf().Build()
+		require.ErrorIs(t, err, ErrEmptyAnyOf)
+		_, err = m.NewQuery().Optional("Unknown").Without("Unknown").Build()
+		require.ErrorIs(t, err, ErrContradictoryQuery)
+		_, err = m.NewQuery().With("Unknown").Build()
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		_, err = m.NewQuery().AnyOf("Unknown").Build()
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+}
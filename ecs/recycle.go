@@ -0,0 +1,182 @@
+package ecs
+
+import (
+	"sort"
+)
+
+// IDRecycler decides in which order the indices of deleted entities are reused by CreateEntity. Implementations must
+// only hold on to as much memory as the indices they currently hold need
+type IDRecycler interface {
+	// Free hands over the index of an entity deleted at the given tick
+	Free(index uint32, tick uint64)
+	// Next returns an index to reuse at the given tick, or false if no index may be reused yet
+	Next(tick uint64) (uint32, bool)
+	// Available returns the number of indices Next would return at the given tick if it was called repeatedly
+	Available(tick uint64) int
+	// Len returns the number of indices held, including the ones that may not be reused yet
+	Len() int
+}
+
+// fifoRecycler reuses the index that was freed first, which spreads reuse over all free indices so generations grow
+// slowly
+type fifoRecycler struct {
+	queue ring[uint32]
+}
+
+// NewFIFORecycler returns an IDRecycler reusing indices in the order they were freed, this is the default policy
+func NewFIFORecycler() IDRecycler {
+	return &fifoRecycler{}
+}
+
+func (r *fifoRecycler) Free(index uint32, _ uint64) {
+	r.queue.push(index)
+}
+
+func (r *fifoRecycler) Next(_ uint64) (uint32, bool) {
+	return r.queue.pop()
+}
+
+func (r *fifoRecycler) Available(_ uint64) int {
+	return r.queue.len()
+}
+
+func (r *fifoRecycler) Len() int {
+	return r.queue.len()
+}
+
+// lifoRecycler reuses the index that was freed last, which keeps the live indices, and so the storage, compact
+type lifoRecycler struct {
+	stack []uint32
+}
+
+// NewLIFORecycler returns an IDRecycler reusing the most recently freed index first
+func NewLIFORecycler() IDRecycler {
+	return &lifoRecycler{stack: make([]uint32, 0)}
+}
+
+func (r *lifoRecycler) Free(index uint32, _ uint64) {
+	r.stack = append(r.stack, index)
+}
+
+func (r *lifoRecycler) Next(_ uint64) (uint32, bool) {
+	if len(r.stack) == 0 {
+		return 0, false
+	}
+	index := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	if cap(r.stack) > ringMinCapacity && len(r.stack) < cap(r.stack)/4 {
+		r.stack = append(make([]uint32, 0, cap(r.stack)/2), r.stack...)
+	}
+	return index, true
+}
+
+func (r *lifoRecycler) Available(_ uint64) int {
+	return len(r.stack)
+}
+
+func (r *lifoRecycler) Len() int {
+	return len(r.stack)
+}
+
+type quarantinedIndex struct {
+	index uint32
+	freed uint64
+}
+
+// quarantineRecycler reuses indices in the order they were freed, but only once they have been free for a number of
+// ticks, so handles kept for a short while after a delete never see their index reused
+type quarantineRecycler struct {
+	ticks uint64
+	queue ring[quarantinedIndex]
+}
+
+// NewQuarantineRecycler returns an IDRecycler reusing indices in the order they were freed, once they have been free
+// for at least the given number of ticks
+func NewQuarantineRecycler(ticks uint64) IDRecycler {
+	return &quarantineRecycler{ticks: ticks}
+}
+
+func (r *quarantineRecycler) Free(index uint32, tick uint64) {
+	r.queue.push(quarantinedIndex{index: index, freed: tick})
+}
+
+func (r *quarantineRecycler) Next(tick uint64) (uint32, bool) {
+	// Indices are queued in the order they were freed, so if the oldest one is still quarantined all of them are
+	oldest, ok := r.queue.peek()
+	if !ok || tick-oldest.freed < r.ticks {
+		return 0, false
+	}
+	r.queue.pop()
+	return oldest.index, true
+}
+
+func (r *quarantineRecycler) Available(tick uint64) int {
+	// Indices are queued in the order they were freed, so the ones out of quarantine come first
+	return sort.Search(r.queue.len(), func(i int) bool {
+		return r.queue.at(i).freed+r.ticks > tick
+	})
+}
+
+func (r *quarantineRecycler) Len() int {
+	return r.queue.len()
+}
+
+// ringMinCapacity is the capacity below which ring buffers and stacks are not shrunk
+const ringMinCapacity = 16
+
+// ring is a FIFO queue stored in a circular buffer, which grows and shrinks with the number of items it holds
+type ring[T any] struct {
+	items []T
+	head  int
+	count int
+}
+
+func (r *ring[T]) len() int {
+	return r.count
+}
+
+func (r *ring[T]) push(item T) {
+	if r.count == len(r.items) {
+		r.resize(max(ringMinCapacity, len(r.items)*2))
+	}
+	r.items[(r.head+r.count)%len(r.items)] = item
+	r.count++
+}
+
+func (r *ring[T]) peek() (T, bool) {
+	if r.count == 0 {
+		var zero T
+		return zero, false
+	}
+	return r.items[r.head], true
+}
+
+// at returns the item at the given position from the head
+func (r *ring[T]) at(i int) T {
+	return r.items[(r.head+i)%len(r.items)]
+}
+
+func (r *ring[T]) pop() (T, bool) {
+	item, ok := r.peek()
+	if !ok {
+		return item, false
+	}
+	var zero T
+	r.items[r.head] = zero
+	r.head = (r.head + 1) % len(r.items)
+	r.count--
+	if len(r.items) > ringMinCapacity && r.count < len(r.items)/4 {
+		r.resize(len(r.items) / 2)
+	}
+	return item, true
+}
+
+// resize moves the items to a buffer of the given capacity, starting at its head
+func (r *ring[T]) resize(capacity int) {
+	items := make([]T, capacity)
+	for i := 0; i < r.count; i++ {
+		items[i] = r.items[(r.head+i)%len(r.items)]
+	}
+	r.items = items
+	r.head = 0
+}
@@ -0,0 +1,178 @@
+package ecs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deleteAndRecreate creates count entities, deletes them in order and returns the indices handed out when creating
+// count entities again
+func deleteAndRecreate(t *testing.T, m *Manager, count int) []uint32 {
+	entities := make([]Entity, count)
+	for i := range entities {
+		entities[i] = m.CreateEntity()
+	}
+	for _, e := range entities {
+		require.NoError(t, m.DeleteEntity(e))
+	}
+	indices := make([]uint32, count)
+	for i := range indices {
+		indices[i] = m.CreateEntity().Index()
+	}
+	return indices
+}
+
+func Test_IDRecycler(t *testing.T) {
+	t.Log("Default policy - reuses indices in the order they were freed")
+	{
+		m := NewManager()
+		require.Equal(t, []uint32{0, 1, 2}, deleteAndRecreate(t, m, 3))
+	}
+
+	t.Log("FIFO policy - reuses indices in the order they were freed")
+	{
+		m := NewManager(WithIDRecycler(NewFIFORecycler()))
+		require.Equal(t, []uint32{0, 1, 2}, deleteAndRecreate(t, m, 3))
+	}
+
+	t.Log("LIFO policy - reuses the most recently freed index first")
+	{
+		m := NewManager(WithIDRecycler(NewLIFORecycler()))
+		require.Equal(t, []uint32{2, 1, 0}, deleteAndRecreate(t, m, 3))
+	}
+
+	t.Log("Quarantine policy - reuses indices only after the quarantine")
+	{
+		m := NewManager(WithIDRecycler(NewQuarantineRecycler(2)))
+		e := m.CreateEntity()
+		require.NoError(t, m.DeleteEntity(e))
+
+		require.Equal(t, uint32(1), m.CreateEntity().Index())
+		m.Tick()
+		require.Equal(t, uint32(2), m.CreateEntity().Index())
+		m.Tick()
+		require.Equal(t, uint64(2), m.CurrentTick())
+
+		reused := m.CreateEntity()
+		require.Equal(t, e.Index(), reused.Index())
+		require.Equal(t, uint32(1), reused.Generation())
+		require.Equal(t, uint32(3), m.CreateEntity().Index())
+	}
+}
+
+func Test_QuarantineRecycler_Available(t *testing.T) {
+	t.Log("Count indices out of quarantine - succeeds")
+	{
+		r := NewQuarantineRecycler(2)
+		for tick := uint64(0); tick < 5; tick++ {
+			r.Free(uint32(2*tick), tick)
+			r.Free(uint32(2*tick+1), tick)
+		}
+		require.Equal(t, 0, r.Available(1))
+		require.Equal(t, 2, r.Available(2))
+		require.Equal(t, 6, r.Available(4))
+		require.Equal(t, 10, r.Available(100))
+	}
+}
+
+func Test_IDRecycler_BoundedMemory(t *testing.T) {
+	for _, r := range []IDRecycler{NewFIFORecycler(), NewLIFORecycler(), NewQuarantineRecycler(0)} {
+		for i := uint32(0); i < 10_000; i++ {
+			r.Free(i, 0)
+		}
+		require.Equal(t, 10_000, r.Len())
+		for i := 0; i < 10_000; i++ {
+			_, ok := r.Next(0)
+			require.True(t, ok)
+		}
+		_, ok := r.Next(0)
+		require.False(t, ok)
+		require.Equal(t, 0, r.Len())
+
+		// Storage shrinks back once the indices have been reused
+		switch r := r.(type) {
+		case *fifoRecycler:
+			require.LessOrEqual(t, len(r.queue.items), ringMinCapacity)
+		case *lifoRecycler:
+			require.LessOrEqual(t, cap(r.stack), ringMinCapacity)
+		case *quarantineRecycler:
+			require.LessOrEqual(t, len(r.queue.items), ringMinCapacity)
+		}
+	}
+}
+
+func Test_Ring(t *testing.T) {
+	var r ring[int]
+	t.Log("Pop empty ring - fails")
+	{
+		_, ok := r.pop()
+		require.False(t, ok)
+	}
+
+	t.Log("Push and pop across the end of the buffer - keeps FIFO order")
+	{
+		next := 0
+		for i := 0; i < 100; i++ {
+			r.push(i)
+			if i%3 == 0 {
+				item, ok := r.pop()
+				require.True(t, ok)
+				require.Equal(t, next, item)
+				next++
+			}
+		}
+		for r.len() > 0 {
+			item, ok := r.pop()
+			require.True(t, ok)
+			require.Equal(t, next, item)
+			next++
+		}
+		require.Equal(t, 100, next)
+	}
+}
+
+func Test_EntityIDsExhausted(t *testing.T) {
+	t.Log("Create more entities than allowed - fails")
+	{
+		m := NewManager(WithMaxEntities(2))
+		m.CreateEntity()
+		e := m.CreateEntity()
+		_, err := m.TryCreateEntity()
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+		require.PanicsWithValue(t, ErrEntityIDsExhausted, func() { m.CreateEntity() })
+		require.Equal(t, 2, m.EntityCount())
+
+		t.Log("Create entity after freeing an index - succeeds")
+		{
+			require.NoError(t, m.DeleteEntity(e))
+			e, err = m.TryCreateEntity()
+			require.NoError(t, err)
+			require.Equal(t, uint32(1), e.Index())
+		}
+	}
+
+	t.Log("Create entity while all free indices are quarantined - fails")
+	{
+		m := NewManager(WithMaxEntities(1), WithIDRecycler(NewQuarantineRecycler(1)))
+		require.NoError(t, m.DeleteEntity(m.CreateEntity()))
+		_, err := m.TryCreateEntity()
+		require.ErrorIs(t, err, ErrEntityIDsExhausted)
+		m.Tick()
+		_, err = m.TryCreateEntity()
+		require.NoError(t, err)
+	}
+
+	t.Log("Delete entity at the last generation - retires its index")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		m.entities[e.Index()].generation = math.MaxUint32
+		e = newEntity(e.Index(), math.MaxUint32)
+		require.NoError(t, m.DeleteEntity(e))
+		require.Equal(t, 0, m.freeIDs.Len())
+		require.ErrorIs(t, m.DeleteEntity(e), ErrEntityNotFound)
+		require.Equal(t, uint32(1), m.CreateEntity().Index())
+	}
+}
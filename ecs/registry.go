@@ -0,0 +1,146 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// ComponentID identifies a component type within a Manager, IDs are assigned in order of registration starting at 0
+type ComponentID uint32
+
+// ComponentInfo describes a registered component type
+type ComponentInfo struct {
+	ID   ComponentID
+	Name string
+	// Type is the Go type of the component data. Component types that accept data of any Go type have the type of `any`
+	Type reflect.Type
+	// Size is the size in bytes of the component data as stored by the Manager
+	Size    uintptr
+	Storage StorageType
+}
+
+// componentInfo is the registry entry of a component type, holding what the Manager needs to store its components
+type componentInfo struct {
+	ComponentInfo
+	// typed is true for types registered with Register, which only accept data of their Go type
+	typed bool
+	// newColumn creates a column that stores the component data unboxed
+	newColumn func() column
+	// check returns ErrComponentDataMismatch if the data cannot be stored in a column of the type
+	check func(data any) error
+	// set holds the components when storage is SparseSetStorage
+	set *sparseSet
+	// relation is true for relation types, whose components hold the targets of the entity and are only changed through
+	// AddRelation and RemoveRelation
+	relation bool
+	// tag is true for tags, whose components hold no data
+	tag bool
+	// hooks holds the lifecycle hooks of the type, or nil if it has none
+	hooks *componentHooks
+	// removals logs the entities that lost a component of the type, or is nil if the type is not tracked
+	removals *removalLog
+}
+
+func newComponentInfo[T any](name string, typed bool, storage StorageType) *componentInfo {
+	goType := reflect.TypeFor[T]()
+	return &componentInfo{
+		ComponentInfo: ComponentInfo{
+			Name:    name,
+			Type:    goType,
+			Size:    goType.Size(),
+			Storage: storage,
+		},
+		typed:     typed,
+		newColumn: newTypedColumn[T],
+		check: func(data any) error {
+			_, err := castData[T](data)
+			return err
+		},
+	}
+}
+
+// ComponentRegistry assigns a ComponentID to every component type known to a Manager, and looks component types up by
+// ID, name or Go type
+type ComponentRegistry struct {
+	components []*componentInfo
+	names      map[string]ComponentID
+	// types maps the Go type of the component types registered with Register to their ID
+	types map[reflect.Type]ComponentID
+}
+
+func newComponentRegistry() *ComponentRegistry {
+	return &ComponentRegistry{
+		components: make([]*componentInfo, 0),
+		names:      make(map[string]ComponentID),
+		types:      make(map[reflect.Type]ComponentID),
+	}
+}
+
+// add assigns the next ID to the component type
+func (r *ComponentRegistry) add(info *componentInfo) *componentInfo {
+	info.ID = ComponentID(len(r.components))
+	r.components = append(r.components, info)
+	r.names[info.Name] = info.ID
+	if info.typed {
+		r.types[info.Type] = info.ID
+	}
+	return info
+}
+
+func (r *ComponentRegistry) byName(name string) (*componentInfo, bool) {
+	id, ok := r.names[name]
+	if !ok {
+		return nil, false
+	}
+	return r.components[id], true
+}
+
+func (r *ComponentRegistry) byType(goType reflect.Type) (*componentInfo, bool) {
+	id, ok := r.types[goType]
+	if !ok {
+		return nil, false
+	}
+	return r.components[id], true
+}
+
+// Len returns the number of registered component types
+func (r *ComponentRegistry) Len() int {
+	return len(r.components)
+}
+
+// ID returns the ID of the component type with the given name
+func (r *ComponentRegistry) ID(name string) (ComponentID, error) {
+	id, ok := r.names[name]
+	if !ok {
+		return 0, ErrComponentTypeNotFound
+	}
+	return id, nil
+}
+
+// Info returns the description of the component type with the given ID
+func (r *ComponentRegistry) Info(id ComponentID) (ComponentInfo, error) {
+	if int(id) >= len(r.components) {
+		return ComponentInfo{}, ErrComponentTypeNotFound
+	}
+	return r.components[id].ComponentInfo, nil
+}
+
+// Lookup returns the description of the component type with the given name
+func (r *ComponentRegistry) Lookup(name string) (ComponentInfo, error) {
+	info, ok := r.byName(name)
+	if !ok {
+		return ComponentInfo{}, ErrComponentTypeNotFound
+	}
+	return info.ComponentInfo, nil
+}
+
+// All returns an iterator over the descriptions of all component types in order of their ID
+func (r *ComponentRegistry) All() iter.Seq[ComponentInfo] {
+	return func(yield func(ComponentInfo) bool) {
+		for _, info := range r.components {
+			if !yield(info.ComponentInfo) {
+				return
+			}
+		}
+	}
+}
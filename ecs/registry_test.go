@@ -0,0 +1,75 @@
+package ecs
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ComponentRegistry(t *testing.T) {
+	m := NewManager()
+	positions, err := Register[testPosition](m, WithName("Position"))
+	require.NoError(t, err)
+	require.NoError(t, m.RegisterComponentType("Velocity", SparseSetStorage))
+	e := m.CreateEntity()
+	require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}}))
+
+	t.Log("Component types get IDs in order of registration")
+	{
+		r := m.Registry()
+		require.Equal(t, 3, r.Len())
+		require.Equal(t, ComponentID(0), positions.ID())
+		for i, name := range []string{"Position", "Velocity", TestComponentStringKey} {
+			id, err := r.ID(name)
+			require.NoError(t, err)
+			require.Equal(t, ComponentID(i), id)
+		}
+	}
+
+	t.Log("Look up typed component type - describes its Go type")
+	{
+		info, err := m.Registry().Lookup("Position")
+		require.NoError(t, err)
+		require.Equal(t, ComponentInfo{
+			ID:      positions.ID(),
+			Name:    "Position",
+			Type:    reflect.TypeFor[testPosition](),
+			Size:    16,
+			Storage: TableStorage,
+		}, info)
+	}
+
+	t.Log("Look up untyped component type - stores data as any")
+	{
+		id, err := m.Registry().ID("Velocity")
+		require.NoError(t, err)
+		info, err := m.Registry().Info(id)
+		require.NoError(t, err)
+		require.Equal(t, "Velocity", info.Name)
+		require.Equal(t, reflect.TypeFor[any](), info.Type)
+		require.Equal(t, reflect.TypeFor[any]().Size(), info.Size)
+		require.Equal(t, SparseSetStorage, info.Storage)
+	}
+
+	t.Log("Look up unknown component type - fails")
+	{
+		_, err := m.Registry().ID("Unknown")
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		_, err = m.Registry().Lookup("Unknown")
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		_, err = m.Registry().Info(ComponentID(42))
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+
+	t.Log("Iterate component types - in order of their ID")
+	{
+		names := make([]string, 0)
+		for info := range m.Registry().All() {
+			names = append(names, info.Name)
+		}
+		require.Equal(t, []string{"Position", "Velocity", TestComponentStringKey}, names)
+		require.Len(t, slices.Collect(m.Registry().All()), 3)
+	}
+}
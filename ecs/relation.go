@@ -0,0 +1,215 @@
+package ecs
+
+import (
+	"errors"
+	"iter"
+	"maps"
+	"slices"
+)
+
+var ErrRelationNotFound = errors.New("relation not found")
+var ErrRelationTypeMismatch = errors.New("component type is a relation, or relation type is a component")
+
+// RegisterRelation registers a relation type, it is registered by AddRelation as well. Relations are stored as a
+// component of the relation type on the source entity, in SparseSetStorage, holding the targets of the source. It
+// returns ErrRelationTypeMismatch if the name is already used by a component type
+func (m *Manager) RegisterRelation(relation string) error {
+	_, err := m.relationInfo(relation, true)
+	return err
+}
+
+// relationInfo returns the registry entry of the relation type, registering it if register is true
+func (m *Manager) relationInfo(relation string, register bool) (*componentInfo, error) {
+	info, ok := m.registry.byName(relation)
+	if !ok {
+		if !register {
+			return nil, ErrComponentTypeNotFound
+		}
+		info = newComponentInfo[[]Entity](relation, false, SparseSetStorage)
+		info.relation = true
+		return m.registerComponent(info), nil
+	}
+	if !info.relation {
+		return nil, ErrRelationTypeMismatch
+	}
+	return info, nil
+}
+
+// relationTargets returns the targets of the source, the source must have a component of the relation type
+func relationTargets(info *componentInfo, source Entity) *[]Entity {
+	return &info.set.column.(*typedColumn[[]Entity]).data[info.set.dense(source)]
+}
+
+// componentData returns the data of the component at the given row of the column of the type. The targets of relations
+// are copied, so that changing them does not bypass AddRelation and RemoveRelation
+func componentData(info *componentInfo, c column, row int) any {
+	data := c.get(row)
+	if info.relation {
+		return slices.Clone(data.([]Entity))
+	}
+	return data
+}
+
+// AddRelation relates source to target with the given relation type, such as source Likes target. A source can have
+// any number of targets for the same relation type, adding a relation that exists already does nothing
+func (m *Manager) AddRelation(source Entity, relation string, target Entity) error {
+	if err := m.checkEntity(source); err != nil {
+		return err
+	}
+	if err := m.checkEntity(target); err != nil {
+		return err
+	}
+	info, err := m.relationInfo(relation, true)
+	if err != nil {
+		return err
+	}
+
+	c, row := m.insertComponent(source, info)
+	targets := relationTargets(info, source)
+	if slices.Contains(*targets, target) {
+		return nil
+	}
+	*targets = append(*targets, target)
+	c.markChanged(row, m.changeTick)
+
+	sources, ok := m.relationSources[target]
+	if !ok {
+		sources = make(map[ComponentID][]Entity)
+		m.relationSources[target] = sources
+	}
+	sources[info.ID] = append(sources[info.ID], source)
+	return nil
+}
+
+// RemoveRelation removes the relation between source and target, it returns ErrRelationNotFound if they are not related
+func (m *Manager) RemoveRelation(source Entity, relation string, target Entity) error {
+	if err := m.checkEntity(source); err != nil {
+		return err
+	}
+	info, err := m.relationInfo(relation, false)
+	if err != nil {
+		return err
+	}
+	if !m.HasRelation(source, relation, target) {
+		return ErrRelationNotFound
+	}
+	m.unrelate(source, info, target)
+	return nil
+}
+
+// HasRelation returns true if source is related to target with the given relation type
+func (m *Manager) HasRelation(source Entity, relation string, target Entity) bool {
+	for t := range m.Targets(source, relation) {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Targets returns an iterator over the targets of source for the given relation type, in the order the relations were
+// added. Relations must not be changed while iterating
+func (m *Manager) Targets(source Entity, relation string) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		info, err := m.relationInfo(relation, false)
+		if err != nil || m.checkEntity(source) != nil || !info.set.has(source) {
+			return
+		}
+		for _, target := range *relationTargets(info, source) {
+			if !yield(target) {
+				return
+			}
+		}
+	}
+}
+
+// Sources returns an iterator over the entities related to target with the given relation type, in the order the
+// relations were added. Relations must not be changed while iterating
+func (m *Manager) Sources(relation string, target Entity) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		info, err := m.relationInfo(relation, false)
+		if err != nil || m.checkEntity(target) != nil {
+			return
+		}
+		for _, source := range m.relationSources[target][info.ID] {
+			if !yield(source) {
+				return
+			}
+		}
+	}
+}
+
+// GetEntitiesWithRelation returns the sources related to target with the given relation type that have all types of
+// components, with their components in the same order as the requested types. The components are copies, changes to
+// them are only stored by passing them to AddComponentToEntity
+func (m *Manager) GetEntitiesWithRelation(relation string, target Entity, types []string) (map[Entity][]*Component, error) {
+	if err := m.checkEntity(target); err != nil {
+		return nil, err
+	}
+	info, err := m.relationInfo(relation, false)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := m.lookupComponentTypes(types)
+	if err != nil {
+		return nil, err
+	}
+
+	f := m.newFilter(infos, nil)
+	result := make(map[Entity][]*Component)
+	for _, source := range m.relationSources[target][info.ID] {
+		if !f.matches(m.entities[source.Index()].signature) {
+			continue
+		}
+		components := make([]*Component, len(infos))
+		for i, info := range infos {
+			c, row, _ := m.componentRow(source, info)
+			components[i] = &Component{Type: info.Name, Data: componentData(info, c, row)}
+		}
+		result[source] = components
+	}
+	return result, nil
+}
+
+// unrelate removes an existing relation, and the relation component of source once it has no targets left
+func (m *Manager) unrelate(source Entity, info *componentInfo, target Entity) {
+	targets := relationTargets(info, source)
+	*targets = slices.DeleteFunc(*targets, func(e Entity) bool { return e == target })
+	if len(*targets) == 0 {
+		m.removeComponent(source, info)
+	}
+
+	sources := m.relationSources[target]
+	sources[info.ID] = slices.DeleteFunc(sources[info.ID], func(e Entity) bool { return e == source })
+	if len(sources[info.ID]) == 0 {
+		delete(sources, info.ID)
+	}
+	if len(sources) == 0 {
+		delete(m.relationSources, target)
+	}
+}
+
+// removeRelations removes all relations of the given type from source
+func (m *Manager) removeRelations(source Entity, info *componentInfo) {
+	for _, target := range slices.Clone(*relationTargets(info, source)) {
+		m.unrelate(source, info, target)
+	}
+}
+
+// deleteRelations removes all relations from and to a live entity that is being deleted
+func (m *Manager) deleteRelations(entity Entity) {
+	for id := range m.entities[entity.Index()].signature.IDs() {
+		if info := m.registry.components[id]; info.relation {
+			m.removeRelations(entity, info)
+		}
+	}
+	// Relation types are visited in order of their IDs so that the order in which sources change does not depend on
+	// the iteration order of the map
+	sources := m.relationSources[entity]
+	for _, id := range slices.Sorted(maps.Keys(sources)) {
+		info := m.registry.components[id]
+		for _, source := range slices.Clone(sources[id]) {
+			m.unrelate(source, info, entity)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddRelation(t *testing.T) {
+	t.Log("Add relations - succeeds")
+	{
+		m := NewManager()
+		alice := m.CreateEntity()
+		bob := m.CreateEntity()
+		carol := m.CreateEntity()
+		require.NoError(t, m.AddRelation(alice, "Likes", bob))
+		require.NoError(t, m.AddRelation(alice, "Likes", carol))
+		require.NoError(t, m.AddRelation(carol, "Likes", bob))
+		require.NoError(t, m.AddRelation(alice, "Likes", bob))
+
+		require.Equal(t, []Entity{bob, carol}, slices.Collect(m.Targets(alice, "Likes")))
+		require.Equal(t, []Entity{alice, carol}, slices.Collect(m.Sources("Likes", bob)))
+		require.True(t, m.HasRelation(carol, "Likes", bob))
+		require.False(t, m.HasRelation(bob, "Likes", carol))
+		require.Empty(t, slices.Collect(m.Targets(alice, "Unknown")))
+
+		info, err := m.Registry().Lookup("Likes")
+		require.NoError(t, err)
+		require.Equal(t, SparseSetStorage, info.Storage)
+		signature, err := m.Signature(alice)
+		require.NoError(t, err)
+		require.True(t, signature.Has(info.ID))
+	}
+
+	t.Log("Change relation targets read as components - does not change the relations")
+	{
+		m := NewManager()
+		alice := m.CreateEntity()
+		bob := m.CreateEntity()
+		carol := m.CreateEntity()
+		require.NoError(t, m.AddRelation(alice, "Likes", bob))
+
+		c, err := m.GetComponentOfEntity(alice, "Likes")
+		require.NoError(t, err)
+		c.Data.([]Entity)[0] = carol
+		result, err := m.GetEntitiesWithComponents([]string{"Likes"})
+		require.NoError(t, err)
+		result[alice][0].Data.([]Entity)[0] = carol
+		q, err := m.NewQuery().With("Likes").Build()
+		require.NoError(t, err)
+		for _, row := range q.All() {
+			data, _ := row.Get(0)
+			data.([]Entity)[0] = carol
+			targets, err := RowData[[]Entity](row, 0)
+			require.NoError(t, err)
+			targets[0] = carol
+		}
+		typed, err := NewQuery1[[]Entity](m.NewQuery(), ReadOnly("Likes"))
+		require.NoError(t, err)
+		require.NoError(t, typed.Each(func(_ Entity, targets *[]Entity) bool {
+			(*targets)[0] = carol
+			return true
+		}))
+
+		require.Equal(t, []Entity{bob}, slices.Collect(m.Targets(alice, "Likes")))
+		require.NoError(t, m.DeleteEntity(bob))
+		require.Empty(t, slices.Collect(m.Targets(alice, "Likes")))
+	}
+
+	t.Log("Add relation with a component type - fails")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 100}))
+		require.ErrorIs(t, m.AddRelation(e, "Health", e), ErrRelationTypeMismatch)
+		require.NoError(t, m.RegisterRelation("Likes"))
+		require.ErrorIs(t, m.AddComponentToEntity(e, Component{Type: "Likes", Data: 1}), ErrRelationTypeMismatch)
+		_, err := m.SpawnBatch(1, Component{Type: "Likes", Data: []Entity{e}})
+		require.ErrorIs(t, err, ErrRelationTypeMismatch)
+	}
+
+	t.Log("Add relation to deleted entity - fails")
+	{
+		m := NewManager()
+		source := m.CreateEntity()
+		target := m.CreateEntity()
+		require.NoError(t, m.DeleteEntity(target))
+		require.ErrorIs(t, m.AddRelation(source, "Likes", target), ErrStaleEntity)
+	}
+}
+
+func Test_RemoveRelation(t *testing.T) {
+	t.Log("Remove relations - succeeds")
+	{
+		m := NewManager()
+		ship := m.CreateEntity()
+		station := m.CreateEntity()
+		require.NoError(t, m.AddRelation(ship, "DockedAt", station))
+		require.NoError(t, m.RemoveRelation(ship, "DockedAt", station))
+		require.False(t, m.HasRelation(ship, "DockedAt", station))
+		require.Empty(t, slices.Collect(m.Sources("DockedAt", station)))
+		signature, err := m.Signature(ship)
+		require.NoError(t, err)
+		require.True(t, signature.IsEmpty())
+		require.Empty(t, m.relationSources)
+		require.ErrorIs(t, m.RemoveRelation(ship, "DockedAt", station), ErrRelationNotFound)
+		require.ErrorIs(t, m.RemoveRelation(ship, "Unknown", station), ErrComponentTypeNotFound)
+	}
+
+	t.Log("Delete relation component - removes all relations of the type")
+	{
+		m := NewManager()
+		source := m.CreateEntity()
+		targets, err := m.CreateEntities(3)
+		require.NoError(t, err)
+		for _, target := range targets {
+			require.NoError(t, m.AddRelation(source, "Targets", target))
+		}
+		require.NoError(t, m.DeleteComponentOfEntity(source, "Targets"))
+		for _, target := range targets {
+			require.Empty(t, slices.Collect(m.Sources("Targets", target)))
+		}
+		require.ErrorIs(t, m.DeleteComponentOfEntity(source, "Targets"), ErrComponentNotFound)
+	}
+}
+
+func Test_DeleteEntityRelations(t *testing.T) {
+	t.Log("Delete target - relations to it are removed")
+	{
+		m := NewManager()
+		a := m.CreateEntity()
+		b := m.CreateEntity()
+		target := m.CreateEntity()
+		other := m.CreateEntity()
+		require.NoError(t, m.AddRelation(a, "Targets", target))
+		require.NoError(t, m.AddRelation(b, "Targets", target))
+		require.NoError(t, m.AddRelation(b, "Targets", other))
+		require.NoError(t, m.AddRelation(target, "Targets", target))
+
+		require.NoError(t, m.DeleteEntity(target))
+		require.Empty(t, slices.Collect(m.Targets(a, "Targets")))
+		require.Equal(t, []Entity{other}, slices.Collect(m.Targets(b, "Targets")))
+		signature, err := m.Signature(a)
+		require.NoError(t, err)
+		require.True(t, signature.IsEmpty())
+
+		// The index of the target is reused without inheriting its relations
+		reused := m.CreateEntity()
+		require.Equal(t, target.Index(), reused.Index())
+		require.Empty(t, slices.Collect(m.Sources("Targets", reused)))
+	}
+
+	t.Log("Delete source - relations from it are removed")
+	{
+		m := NewManager()
+		source := m.CreateEntity()
+		target := m.CreateEntity()
+		require.NoError(t, m.AddRelation(source, "Likes", target))
+		require.NoError(t, m.DeleteEntity(source))
+		require.Empty(t, slices.Collect(m.Sources("Likes", target)))
+		require.Empty(t, m.relationSources)
+	}
+}
+
+func Test_GetEntitiesWithRelation(t *testing.T) {
+	t.Log("Get entities with Vector2 that target X - succeeds")
+	{
+		m := NewManager()
+		x := m.CreateEntity()
+		y := m.CreateEntity()
+		entities, err := m.CreateEntities(3)
+		require.NoError(t, err)
+		for i, e := range entities {
+			require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Vector2", Data: i}))
+		}
+		noVector := m.CreateEntity()
+		require.NoError(t, m.AddRelation(entities[0], "Targets", x))
+		require.NoError(t, m.AddRelation(entities[1], "Targets", y))
+		require.NoError(t, m.AddRelation(entities[2], "Targets", x))
+		require.NoError(t, m.AddRelation(noVector, "Targets", x))
+
+		result, err := m.GetEntitiesWithRelation("Targets", x, []string{"Vector2"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, 0, result[entities[0]][0].Data)
+		require.Equal(t, 2, result[entities[2]][0].Data)
+
+		_, err = m.GetEntitiesWithRelation("Targets", x, []string{"Unknown"})
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+}
@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"iter"
+	"reflect"
+)
+
+// removal records that an entity lost a component, or was deleted
+type removal struct {
+	entity Entity
+	// changeTick is the change tick at which the removal happened, which decides which system runs report it
+	changeTick uint64
+	// tick is the world tick at which the removal happened, which decides when it is dropped from the log
+	tick uint64
+}
+
+// removalLog holds the removals of the current and the previous world tick, in the order they happened
+type removalLog struct {
+	entries ring[removal]
+}
+
+func (l *removalLog) record(entity Entity, changeTick uint64, tick uint64) {
+	l.entries.push(removal{entity: entity, changeTick: changeTick, tick: tick})
+}
+
+// prune drops the removals that happened before the previous world tick
+func (l *removalLog) prune(tick uint64) {
+	for {
+		oldest, ok := l.entries.peek()
+		if !ok || oldest.tick+1 >= tick {
+			return
+		}
+		l.entries.pop()
+	}
+}
+
+// each returns an iterator over the entities of the removals reported to the current run of the system
+func (l *removalLog) each(system *SystemTicks) iter.Seq[Entity] {
+	return func(yield func(Entity) bool) {
+		if l == nil {
+			return
+		}
+		for i := 0; i < l.entries.len(); i++ {
+			entry := l.entries.at(i)
+			if system.reported(entry.changeTick) && !yield(entry.entity) {
+				return
+			}
+		}
+	}
+}
+
+// TrackRemovals starts logging the entities that lose a component of the given type, by DeleteComponentOfEntity and
+// the like or by being deleted, so that systems can read them with RemovedType. Removals are kept for the current and
+// the previous world tick, so systems running at least once per Tick see all of them
+func (m *Manager) TrackRemovals(componentType string) error {
+	info, ok := m.registry.byName(componentType)
+	if !ok {
+		return ErrComponentTypeNotFound
+	}
+	if info.removals == nil {
+		info.removals = &removalLog{}
+		m.removalLogs = append(m.removalLogs, info.removals)
+	}
+	return nil
+}
+
+// TrackDespawns starts logging the entities that are deleted, so that systems can read them with Despawned. Deletions
+// are kept for the current and the previous world tick, so systems running at least once per Tick see all of them
+func (m *Manager) TrackDespawns() {
+	if m.despawns == nil {
+		m.despawns = &removalLog{}
+		m.removalLogs = append(m.removalLogs, m.despawns)
+	}
+}
+
+// RemovedType returns an iterator over the entities that lost a component of the given type since the system last
+// ran, in the order the components were removed. An entity appears once for every time it lost the component. Types
+// that are not tracked with TrackRemovals have no removals
+func (m *Manager) RemovedType(componentType string, system *SystemTicks) iter.Seq[Entity] {
+	info, ok := m.registry.byName(componentType)
+	if !ok {
+		return (*removalLog)(nil).each(system)
+	}
+	return info.removals.each(system)
+}
+
+// Removed returns an iterator over the entities that lost a component of type T since the system last ran, see
+// RemovedType
+func Removed[T any](m *Manager, system *SystemTicks) iter.Seq[Entity] {
+	info, ok := m.registry.byType(reflect.TypeFor[T]())
+	if !ok {
+		return (*removalLog)(nil).each(system)
+	}
+	return info.removals.each(system)
+}
+
+// Despawned returns an iterator over the entities deleted since the system last ran, in the order they were deleted.
+// Deletions are only logged after TrackDespawns has been called
+func (m *Manager) Despawned(system *SystemTicks) iter.Seq[Entity] {
+	return m.despawns.each(system)
+}
+
+// recordRemoval logs that the entity lost the component if the type is tracked
+func (m *Manager) recordRemoval(entity Entity, info *componentInfo) {
+	if info.removals != nil {
+		info.removals.record(entity, m.changeTick, m.tick)
+	}
+}
@@ -0,0 +1,93 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RemovalLog(t *testing.T) {
+	t.Log("Removed components are reported once per system - succeeds")
+	{
+		m := NewManager()
+		positions, err := Register[testPosition](m)
+		require.NoError(t, err)
+		require.NoError(t, m.TrackRemovals(positions.Name()))
+		entities, err := m.SpawnBatch(3, Component{Type: positions.Name(), Data: testPosition{}})
+		require.NoError(t, err)
+
+		var index, renderer SystemTicks
+		index.Begin(m)
+		require.Empty(t, slices.Collect(Removed[testPosition](m, &index)))
+
+		require.NoError(t, positions.Remove(entities[0]))
+		require.NoError(t, m.DeleteEntity(entities[1]))
+		index.Begin(m)
+		require.Equal(t, []Entity{entities[0], entities[1]}, slices.Collect(Removed[testPosition](m, &index)))
+		index.Begin(m)
+		require.Empty(t, slices.Collect(Removed[testPosition](m, &index)))
+
+		renderer.Begin(m)
+		require.Equal(t, []Entity{entities[0], entities[1]}, slices.Collect(m.RemovedType(positions.Name(), &renderer)))
+	}
+
+	t.Log("Removals are dropped after two ticks - succeeds")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterTag("Frozen", TableStorage))
+		require.NoError(t, m.TrackRemovals("Frozen"))
+		e := m.CreateEntity()
+		require.NoError(t, m.AddTag(e, "Frozen"))
+		require.NoError(t, m.RemoveTag(e, "Frozen"))
+
+		var system SystemTicks
+		m.Tick()
+		system.Begin(m)
+		require.Equal(t, []Entity{e}, slices.Collect(m.RemovedType("Frozen", &system)))
+
+		var late SystemTicks
+		m.Tick()
+		late.Begin(m)
+		require.Empty(t, slices.Collect(m.RemovedType("Frozen", &late)))
+		require.Equal(t, 0, m.registry.components[0].removals.entries.len())
+	}
+
+	t.Log("Untracked and unknown types - have no removals")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 1}))
+		require.NoError(t, m.DeleteComponentOfEntity(e, "Health"))
+		var system SystemTicks
+		system.Begin(m)
+		require.Empty(t, slices.Collect(m.RemovedType("Health", &system)))
+		require.Empty(t, slices.Collect(m.RemovedType("Unknown", &system)))
+		require.Empty(t, slices.Collect(Removed[testPosition](m, &system)))
+		require.ErrorIs(t, m.TrackRemovals("Unknown"), ErrComponentTypeNotFound)
+	}
+}
+
+func Test_DespawnLog(t *testing.T) {
+	t.Log("Despawned entities are reported once per system - succeeds")
+	{
+		m := NewManager()
+		entities, err := m.CreateEntities(4)
+		require.NoError(t, err)
+		require.NoError(t, m.DeleteEntity(entities[0]))
+		m.TrackDespawns()
+		require.NoError(t, m.SetParent(entities[2], entities[1]))
+
+		var replication SystemTicks
+		replication.Begin(m)
+		require.NoError(t, m.DeleteEntityRecursive(entities[1]))
+		require.Empty(t, slices.Collect(m.Despawned(&replication)))
+
+		replication.Begin(m)
+		require.Equal(t, []Entity{entities[1], entities[2]}, slices.Collect(m.Despawned(&replication)))
+		for e := range m.Despawned(&replication) {
+			require.Equal(t, entities[1], e)
+			break
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package ecs
+
+import (
+	"errors"
+	"reflect"
+)
+
+var ErrResourceNotFound = errors.New("resource not found")
+
+// InsertResource stores the value as the world resource of type T, such as the current time, the RNG or the config,
+// which belongs to the Manager rather than to an entity. A resource of type T that is already stored is overwritten in
+// place, so pointers returned by Resource stay valid
+func InsertResource[T any](m *Manager, value T) {
+	goType := reflect.TypeFor[T]()
+	if resource, ok := m.resources[goType]; ok {
+		*resource.(*T) = value
+		return
+	}
+	m.resources[goType] = &value
+}
+
+// Resource returns a pointer to the world resource of type T, changes made through it are stored directly. It returns
+// ErrResourceNotFound if no resource of type T has been inserted
+func Resource[T any](m *Manager) (*T, error) {
+	resource, ok := m.resources[reflect.TypeFor[T]()]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	return resource.(*T), nil
+}
+
+// HasResource returns true if a resource of type T has been inserted
+func HasResource[T any](m *Manager) bool {
+	_, ok := m.resources[reflect.TypeFor[T]()]
+	return ok
+}
+
+// RemoveResource removes the world resource of type T, it returns ErrResourceNotFound if there is none
+func RemoveResource[T any](m *Manager) error {
+	goType := reflect.TypeFor[T]()
+	if _, ok := m.resources[goType]; !ok {
+		return ErrResourceNotFound
+	}
+	delete(m.resources, goType)
+	return nil
+}
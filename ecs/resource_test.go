@@ -0,0 +1,68 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testTime struct {
+	Delta float64
+	Total float64
+}
+
+func Test_Resource(t *testing.T) {
+	t.Log("Insert, read and update resource - succeeds")
+	{
+		m := NewManager()
+		require.False(t, HasResource[testTime](m))
+		InsertResource(m, testTime{Delta: 0.5})
+		require.True(t, HasResource[testTime](m))
+
+		time, err := Resource[testTime](m)
+		require.NoError(t, err)
+		require.Equal(t, testTime{Delta: 0.5}, *time)
+		time.Total += time.Delta
+
+		again, err := Resource[testTime](m)
+		require.NoError(t, err)
+		require.Equal(t, testTime{Delta: 0.5, Total: 0.5}, *again)
+		require.Equal(t, 0, m.EntityCount())
+	}
+
+	t.Log("Insert resource of the same type - overwrites it in place")
+	{
+		m := NewManager()
+		InsertResource(m, testTime{Delta: 1})
+		time, err := Resource[testTime](m)
+		require.NoError(t, err)
+		InsertResource(m, testTime{Delta: 2})
+		require.Equal(t, testTime{Delta: 2}, *time)
+	}
+
+	t.Log("Resources are keyed by Go type - succeeds")
+	{
+		m := NewManager()
+		InsertResource(m, 42)
+		InsertResource(m, "config")
+		InsertResource(m, &testTime{Delta: 1})
+		number, err := Resource[int](m)
+		require.NoError(t, err)
+		require.Equal(t, 42, *number)
+		pointer, err := Resource[*testTime](m)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, (*pointer).Delta)
+		_, err = Resource[testTime](m)
+		require.ErrorIs(t, err, ErrResourceNotFound)
+	}
+
+	t.Log("Remove resource - succeeds")
+	{
+		m := NewManager()
+		InsertResource(m, testTime{})
+		require.NoError(t, RemoveResource[testTime](m))
+		_, err := Resource[testTime](m)
+		require.ErrorIs(t, err, ErrResourceNotFound)
+		require.ErrorIs(t, RemoveResource[testTime](m), ErrResourceNotFound)
+	}
+}
@@ -0,0 +1,128 @@
+package ecs
+
+import (
+	"iter"
+	"math/bits"
+)
+
+// Signature is a set of ComponentIDs stored as a bitset. It grows to fit the highest ID it holds, so it supports any
+// number of component types
+type Signature struct {
+	words []uint64
+}
+
+// NewSignature returns a signature holding the given IDs
+func NewSignature(ids ...ComponentID) Signature {
+	var s Signature
+	for _, id := range ids {
+		s.Set(id)
+	}
+	return s
+}
+
+// Set adds the ID to the signature
+func (s *Signature) Set(id ComponentID) {
+	word := int(id / 64)
+	if word >= len(s.words) {
+		s.words = append(s.words, make([]uint64, word+1-len(s.words))...)
+	}
+	s.words[word] |= 1 << (id % 64)
+}
+
+// Clear removes the ID from the signature
+func (s *Signature) Clear(id ComponentID) {
+	word := int(id / 64)
+	if word < len(s.words) {
+		s.words[word] &^= 1 << (id % 64)
+	}
+}
+
+// Has returns true if the signature holds the ID
+func (s Signature) Has(id ComponentID) bool {
+	word := int(id / 64)
+	return word < len(s.words) && s.words[word]&(1<<(id%64)) != 0
+}
+
+// ContainsAll returns true if the signature holds every ID of the other signature
+func (s Signature) ContainsAll(other Signature) bool {
+	for i, w := range other.words {
+		if i >= len(s.words) {
+			if w != 0 {
+				return false
+			}
+			continue
+		}
+		if s.words[i]&w != w {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects returns true if the signature holds any ID of the other signature
+func (s Signature) Intersects(other Signature) bool {
+	for i := 0; i < len(s.words) && i < len(other.words); i++ {
+		if s.words[i]&other.words[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty returns true if the signature holds no IDs
+func (s Signature) IsEmpty() bool {
+	for _, w := range s.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reset removes all IDs from the signature, keeping its storage
+func (s *Signature) reset() {
+	clear(s.words)
+}
+
+// assign makes the signature hold the same IDs as the other signature, reusing its own storage
+func (s *Signature) assign(other Signature) {
+	s.words = append(s.words[:0], other.words...)
+}
+
+// Count returns the number of IDs in the signature
+func (s Signature) Count() int {
+	count := 0
+	for _, w := range s.words {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// Clone returns a copy of the signature that does not share storage with it
+func (s Signature) Clone() Signature {
+	return Signature{words: append([]uint64(nil), s.words...)}
+}
+
+// without returns a copy of the signature without the IDs of the other signature
+func (s Signature) without(other Signature) Signature {
+	result := s.Clone()
+	for i := 0; i < len(result.words) && i < len(other.words); i++ {
+		result.words[i] &^= other.words[i]
+	}
+	return result
+}
+
+// IDs returns an iterator over the IDs in the signature in ascending order
+func (s Signature) IDs() iter.Seq[ComponentID] {
+	return func(yield func(ComponentID) bool) {
+		for i, w := range s.words {
+			for w != 0 {
+				bit := bits.TrailingZeros64(w)
+				if !yield(ComponentID(i*64 + bit)) {
+					return
+				}
+				w &^= 1 << bit
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package ecs
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Signature(t *testing.T) {
+	t.Log("Set and clear IDs beyond 64 - succeeds")
+	{
+		s := NewSignature(1, 63, 64, 200)
+		require.True(t, s.Has(1))
+		require.True(t, s.Has(63))
+		require.True(t, s.Has(64))
+		require.True(t, s.Has(200))
+		require.False(t, s.Has(2))
+		require.False(t, s.Has(1000))
+		require.Equal(t, 4, s.Count())
+		require.Equal(t, []ComponentID{1, 63, 64, 200}, slices.Collect(s.IDs()))
+
+		s.Clear(64)
+		s.Clear(1000)
+		require.False(t, s.Has(64))
+		require.Equal(t, []ComponentID{1, 63, 200}, slices.Collect(s.IDs()))
+	}
+
+	t.Log("Compare signatures of different lengths - succeeds")
+	{
+		s := NewSignature(1, 70, 130)
+		require.True(t, s.ContainsAll(NewSignature()))
+		require.True(t, s.ContainsAll(NewSignature(1, 130)))
+		require.False(t, s.ContainsAll(NewSignature(1, 2)))
+		require.False(t, s.ContainsAll(NewSignature(300)))
+		require.False(t, NewSignature(1).ContainsAll(s))
+
+		require.True(t, s.Intersects(NewSignature(2, 70)))
+		require.False(t, s.Intersects(NewSignature(2, 71, 300)))
+		require.False(t, s.Intersects(NewSignature()))
+	}
+
+	t.Log("Empty signatures - succeeds")
+	{
+		s := NewSignature(100)
+		require.False(t, s.IsEmpty())
+		s.Clear(100)
+		require.True(t, s.IsEmpty())
+		require.True(t, NewSignature().IsEmpty())
+	}
+
+	t.Log("Clone signature - does not share storage")
+	{
+		s := NewSignature(5)
+		c := s.Clone()
+		c.Set(6)
+		s.Clear(5)
+		require.True(t, c.Has(5))
+		require.False(t, s.Has(6))
+		require.Equal(t, NewSignature(5, 7), NewSignature(5, 6, 7).without(NewSignature(6, 100)))
+	}
+}
+
+func Test_Manager_Signature(t *testing.T) {
+	m := NewManager()
+	require.NoError(t, m.RegisterComponentType("Sparse", SparseSetStorage))
+	sparseID, err := m.Registry().ID("Sparse")
+	require.NoError(t, err)
+
+	e := m.CreateEntity()
+	require.NoError(t, m.AddComponentToEntity(e, Component{Type: TestComponentStringKey, Data: TestComponentString{content: "Hello"}}))
+	require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Sparse"}))
+	stringID, err := m.Registry().ID(TestComponentStringKey)
+	require.NoError(t, err)
+
+	t.Log("Signature holds table and sparse set component types")
+	{
+		s, err := m.Signature(e)
+		require.NoError(t, err)
+		require.Equal(t, []ComponentID{sparseID, stringID}, slices.Collect(s.IDs()))
+	}
+
+	t.Log("Signature follows deleted components")
+	{
+		require.NoError(t, m.DeleteComponentOfEntity(e, "Sparse"))
+		s, err := m.Signature(e)
+		require.NoError(t, err)
+		require.Equal(t, []ComponentID{stringID}, slices.Collect(s.IDs()))
+	}
+
+	t.Log("Signature of deleted entity - fails, and the recycled entity starts empty")
+	{
+		require.NoError(t, m.DeleteEntity(e))
+		_, err := m.Signature(e)
+		require.ErrorIs(t, err, ErrStaleEntity)
+
+		e = m.CreateEntity()
+		s, err := m.Signature(e)
+		require.NoError(t, err)
+		require.True(t, s.IsEmpty())
+	}
+}
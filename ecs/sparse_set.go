@@ -0,0 +1,80 @@
+package ecs
+
+import (
+	"slices"
+)
+
+// sparseSet stores the components of a single type outside of the archetypes. Component data and entities are kept in
+// dense arrays, and sparse maps the index of an entity to its row in the dense arrays, so adding and removing a
+// component never moves the rest of the components of the entity
+type sparseSet struct {
+	// sparse holds the dense row + 1 of each entity index, 0 means the entity does not have the component
+	sparse   []uint32
+	entities []Entity
+	column   column
+}
+
+func newSparseSet(c column) *sparseSet {
+	return &sparseSet{
+		sparse:   make([]uint32, 0),
+		entities: make([]Entity, 0),
+		column:   c,
+	}
+}
+
+func (s *sparseSet) len() int {
+	return len(s.entities)
+}
+
+// dense returns the row of the entity in the dense arrays, or -1 if it does not have a component in the set
+func (s *sparseSet) dense(entity Entity) int {
+	index := entity.Index()
+	if int(index) >= len(s.sparse) || s.sparse[index] == 0 {
+		return -1
+	}
+	return int(s.sparse[index] - 1)
+}
+
+// reserve makes room for n more entities without reallocating the dense arrays
+func (s *sparseSet) reserve(n int) {
+	s.entities = slices.Grow(s.entities, n)
+	s.column.reserve(n)
+}
+
+func (s *sparseSet) has(entity Entity) bool {
+	return s.dense(entity) >= 0
+}
+
+// insert returns the row of the entity, adding a row holding the zero value if the entity is not in the set yet
+func (s *sparseSet) insert(entity Entity) int {
+	if i := s.dense(entity); i >= 0 {
+		return i
+	}
+
+	index := entity.Index()
+	if int(index) >= len(s.sparse) {
+		s.sparse = append(s.sparse, make([]uint32, int(index)+1-len(s.sparse))...)
+	}
+	s.entities = append(s.entities, entity)
+	s.column.appendZero()
+	s.sparse[index] = uint32(len(s.entities))
+	return len(s.entities) - 1
+}
+
+// remove removes the component of the entity by moving the last component into its place, it returns false if the
+// entity did not have a component in the set
+func (s *sparseSet) remove(entity Entity) bool {
+	i := s.dense(entity)
+	if i < 0 {
+		return false
+	}
+
+	last := len(s.entities) - 1
+	moved := s.entities[last]
+	s.entities[i] = moved
+	s.entities = s.entities[:last]
+	s.column.removeRow(i)
+	s.sparse[moved.Index()] = uint32(i + 1)
+	s.sparse[entity.Index()] = 0
+	return true
+}
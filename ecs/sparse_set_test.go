@@ -0,0 +1,57 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SparseSet(t *testing.T) {
+	s := newSparseSet(newTypedColumn[int]())
+	data := func(entity Entity) int {
+		return s.column.(*typedColumn[int]).data[s.dense(entity)]
+	}
+
+	t.Log("Look up entity not in the set - returns -1")
+	{
+		require.Equal(t, -1, s.dense(0))
+		require.False(t, s.has(100))
+	}
+
+	t.Log("Insert entities - succeeds")
+	{
+		for i := 0; i < 5; i++ {
+			row := s.insert(newEntity(uint32(i*10), 0))
+			require.Equal(t, i, row)
+			require.NoError(t, s.column.set(row, i))
+		}
+		require.Equal(t, 5, s.len())
+		require.Equal(t, 3, data(30))
+		require.False(t, s.has(35))
+	}
+
+	t.Log("Insert entity already in the set - returns its row")
+	{
+		require.Equal(t, 2, s.insert(20))
+		require.Equal(t, 5, s.len())
+		require.Equal(t, 2, data(20))
+	}
+
+	t.Log("Remove entity - moves the last entity into its place")
+	{
+		require.True(t, s.remove(10))
+		require.False(t, s.remove(10))
+		require.False(t, s.has(10))
+		require.Equal(t, 4, s.len())
+		require.Equal(t, []Entity{0, 40, 20, 30}, s.entities)
+		require.Equal(t, []int{0, 4, 2, 3}, s.column.(*typedColumn[int]).data)
+		require.Equal(t, 4, data(40))
+	}
+
+	t.Log("Remove last entity - succeeds")
+	{
+		require.True(t, s.remove(30))
+		require.Equal(t, []Entity{0, 40, 20}, s.entities)
+		require.Equal(t, []int{0, 4, 2}, s.column.(*typedColumn[int]).data)
+	}
+}
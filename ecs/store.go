@@ -0,0 +1,198 @@
+package ecs
+
+import (
+	"errors"
+	"reflect"
+)
+
+var ErrComponentTypeRegistered = errors.New("component type is already registered")
+
+type componentOptions struct {
+	name    string
+	storage StorageType
+}
+
+// ComponentOption configures a component type registered with Register
+type ComponentOption func(*componentOptions)
+
+// WithName sets the name of the component type, used as the Type of its components in the string based API
+func WithName(name string) ComponentOption {
+	return func(o *componentOptions) {
+		o.name = name
+	}
+}
+
+// WithStorage sets the storage type of the component type
+func WithStorage(storage StorageType) ComponentOption {
+	return func(o *componentOptions) {
+		o.storage = storage
+	}
+}
+
+// ComponentStore gives compile-time typed access to the components of type T, which are stored unboxed
+type ComponentStore[T any] struct {
+	m    *Manager
+	info *componentInfo
+}
+
+// Register registers T as a component type and returns its store. The type is named after the Go type of T unless
+// WithName is given, and uses TableStorage unless WithStorage is given. Registering T again with the same options
+// returns a store for the existing registration. Every Go type can only be registered once, so two component types
+// sharing a Go type need distinct named types, e.g. `type Velocity2D r2.Vec`
+func Register[T any](m *Manager, options ...ComponentOption) (*ComponentStore[T], error) {
+	goType := reflect.TypeFor[T]()
+	opts := componentOptions{name: goType.String(), storage: TableStorage}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if info, ok := m.registry.byType(goType); ok {
+		if info.Name != opts.name {
+			return nil, ErrComponentTypeRegistered
+		}
+		if info.Storage != opts.storage {
+			return nil, ErrStorageTypeMismatch
+		}
+		return &ComponentStore[T]{m: m, info: info}, nil
+	}
+	if _, ok := m.registry.byName(opts.name); ok {
+		return nil, ErrComponentTypeRegistered
+	}
+
+	info := m.registerComponent(newComponentInfo[T](opts.name, true, opts.storage))
+	return &ComponentStore[T]{m: m, info: info}, nil
+}
+
+// Store returns the store of T, or ErrComponentTypeNotFound if T has not been registered
+func Store[T any](m *Manager) (*ComponentStore[T], error) {
+	info, ok := m.registry.byType(reflect.TypeFor[T]())
+	if !ok {
+		return nil, ErrComponentTypeNotFound
+	}
+	return &ComponentStore[T]{m: m, info: info}, nil
+}
+
+// ID returns the ID of the component type
+func (s *ComponentStore[T]) ID() ComponentID {
+	return s.info.ID
+}
+
+// Name returns the name of the component type
+func (s *ComponentStore[T]) Name() string {
+	return s.info.Name
+}
+
+// Get returns a pointer to the component of the entity. The pointer points into the storage of the Manager, so
+// changes made through it are stored directly. It is only valid until a component is next added to or removed from
+// any entity. Changes made through it are not detected by Changed filters, use GetMut for that
+func (s *ComponentStore[T]) Get(entity Entity) (*T, error) {
+	if err := s.m.checkEntity(entity); err != nil {
+		return nil, err
+	}
+	c, row, ok := s.m.componentRow(entity, s.info)
+	if !ok {
+		return nil, ErrComponentNotFound
+	}
+	return &c.(*typedColumn[T]).data[row], nil
+}
+
+// GetMut works like Get, but marks the component as changed, so that Changed filters report it
+func (s *ComponentStore[T]) GetMut(entity Entity) (*T, error) {
+	if err := s.m.checkEntity(entity); err != nil {
+		return nil, err
+	}
+	c, row, ok := s.m.componentRow(entity, s.info)
+	if !ok {
+		return nil, ErrComponentNotFound
+	}
+	c.markChanged(row, s.m.changeTick)
+	return &c.(*typedColumn[T]).data[row], nil
+}
+
+// Set adds the component to the entity, replacing the component of the entity if it already has one
+func (s *ComponentStore[T]) Set(entity Entity, value T) error {
+	if err := s.m.checkEntity(entity); err != nil {
+		return err
+	}
+	if s.info.hooks != nil {
+		s.m.setComponent(entity, s.info, value)
+		s.m.runHooks()
+		return nil
+	}
+	c, row, existed := s.m.componentRow(entity, s.info)
+	if existed {
+		c.markChanged(row, s.m.changeTick)
+	} else {
+		c, row = s.m.insertComponent(entity, s.info)
+	}
+	c.(*typedColumn[T]).data[row] = value
+	return nil
+}
+
+// Has returns true if the entity is alive and has the component
+func (s *ComponentStore[T]) Has(entity Entity) bool {
+	if s.m.checkEntity(entity) != nil {
+		return false
+	}
+	_, _, ok := s.m.componentRow(entity, s.info)
+	return ok
+}
+
+// Remove removes the component from the entity
+func (s *ComponentStore[T]) Remove(entity Entity) error {
+	if err := s.m.checkEntity(entity); err != nil {
+		return err
+	}
+	if !s.m.removeComponent(entity, s.info) {
+		return ErrComponentNotFound
+	}
+	s.m.runHooks()
+	return nil
+}
+
+// Get returns a pointer to the component of type T of the entity, see ComponentStore.Get
+func Get[T any](m *Manager, entity Entity) (*T, error) {
+	s, err := Store[T](m)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(entity)
+}
+
+// GetMut returns a pointer to the component of type T of the entity and marks it as changed, see ComponentStore.GetMut
+func GetMut[T any](m *Manager, entity Entity) (*T, error) {
+	s, err := Store[T](m)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetMut(entity)
+}
+
+// Set adds the component of type T to the entity, registering T with the default options if it is not registered yet
+func Set[T any](m *Manager, entity Entity, value T) error {
+	s, err := Store[T](m)
+	if err != nil {
+		if s, err = Register[T](m); err != nil {
+			return err
+		}
+	}
+	return s.Set(entity, value)
+}
+
+// Has returns true if the entity is alive and has a component of type T
+func Has[T any](m *Manager, entity Entity) bool {
+	s, err := Store[T](m)
+	if err != nil {
+		return false
+	}
+	return s.Has(entity)
+}
+
+// Remove removes the component of type T from the entity
+func Remove[T any](m *Manager, entity Entity) error {
+	s, err := Store[T](m)
+	if err != nil {
+		return err
+	}
+	return s.Remove(entity)
+}
@@ -0,0 +1,224 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPosition struct {
+	X, Y float64
+}
+
+type testVelocity struct {
+	X, Y float64
+}
+
+func Test_Register(t *testing.T) {
+	t.Log("Register type - named after the Go type")
+	{
+		m := NewManager()
+		s, err := Register[testPosition](m)
+		require.NoError(t, err)
+		require.Equal(t, "ecs.testPosition", s.Name())
+	}
+
+	t.Log("Register type with options - succeeds")
+	{
+		m := NewManager()
+		s, err := Register[testPosition](m, WithName("Position"), WithStorage(SparseSetStorage))
+		require.NoError(t, err)
+		require.Equal(t, "Position", s.Name())
+		info, err := m.Registry().Lookup("Position")
+		require.NoError(t, err)
+		require.Equal(t, s.ID(), info.ID)
+		require.Equal(t, SparseSetStorage, info.Storage)
+	}
+
+	t.Log("Register type again with the same options - returns the existing store")
+	{
+		m := NewManager()
+		s1, err := Register[testPosition](m, WithName("Position"))
+		require.NoError(t, err)
+		e := m.CreateEntity()
+		require.NoError(t, s1.Set(e, testPosition{X: 1}))
+
+		s2, err := Register[testPosition](m, WithName("Position"))
+		require.NoError(t, err)
+		require.True(t, s2.Has(e))
+	}
+
+	t.Log("Register type again with different options - fails")
+	{
+		m := NewManager()
+		_, err := Register[testPosition](m, WithName("Position"))
+		require.NoError(t, err)
+		_, err = Register[testPosition](m, WithName("OtherPosition"))
+		require.ErrorIs(t, err, ErrComponentTypeRegistered)
+		_, err = Register[testPosition](m, WithName("Position"), WithStorage(SparseSetStorage))
+		require.ErrorIs(t, err, ErrStorageTypeMismatch)
+	}
+
+	t.Log("Register type with a name that is already used - fails")
+	{
+		m := NewManager()
+		_, err := Register[testPosition](m, WithName("Position"))
+		require.NoError(t, err)
+		_, err = Register[testVelocity](m, WithName("Position"))
+		require.ErrorIs(t, err, ErrComponentTypeRegistered)
+
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Velocity", Data: testVelocity{}}))
+		_, err = Register[testVelocity](m, WithName("Velocity"))
+		require.ErrorIs(t, err, ErrComponentTypeRegistered)
+	}
+}
+
+func Test_ComponentStore(t *testing.T) {
+	for _, storage := range []StorageType{TableStorage, SparseSetStorage} {
+		m := NewManager()
+		positions, err := Register[testPosition](m, WithStorage(storage))
+		require.NoError(t, err)
+		velocities, err := Register[testVelocity](m)
+		require.NoError(t, err)
+		e1 := m.CreateEntity()
+		e2 := m.CreateEntity()
+
+		t.Log("Get component the entity does not have - fails")
+		{
+			p, err := positions.Get(e1)
+			require.ErrorIs(t, err, ErrComponentNotFound)
+			require.Nil(t, p)
+			require.False(t, positions.Has(e1))
+			require.ErrorIs(t, positions.Remove(e1), ErrComponentNotFound)
+		}
+
+		t.Log("Set and get components - succeeds")
+		{
+			require.NoError(t, positions.Set(e1, testPosition{X: 1, Y: 2}))
+			require.NoError(t, positions.Set(e2, testPosition{X: 3, Y: 4}))
+			require.NoError(t, velocities.Set(e1, testVelocity{X: 1, Y: 1}))
+			require.True(t, positions.Has(e1))
+
+			p, err := positions.Get(e1)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 1, Y: 2}, *p)
+			p, err = positions.Get(e2)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 3, Y: 4}, *p)
+		}
+
+		t.Log("Modify component through pointer - stores the change")
+		{
+			p, err := positions.Get(e1)
+			require.NoError(t, err)
+			v, err := velocities.Get(e1)
+			require.NoError(t, err)
+			p.X += v.X
+			p.Y += v.Y
+
+			p, err = positions.Get(e1)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 2, Y: 3}, *p)
+		}
+
+		t.Log("Typed components - visible through the string based API")
+		{
+			c, err := m.GetComponentOfEntity(e1, positions.Name())
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 2, Y: 3}, c.Data)
+
+			require.NoError(t, m.AddComponentToEntity(e2, Component{Type: positions.Name(), Data: testPosition{X: 5, Y: 5}}))
+			p, err := positions.Get(e2)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 5, Y: 5}, *p)
+
+			ec, err := m.GetEntitiesWithComponents([]string{positions.Name(), velocities.Name()})
+			require.NoError(t, err)
+			require.Len(t, ec, 1)
+			require.Equal(t, testVelocity{X: 1, Y: 1}, ec[e1][1].Data)
+		}
+
+		t.Log("Add component of the wrong Go type through the string based API - fails")
+		{
+			err := m.AddComponentToEntity(e2, Component{Type: positions.Name(), Data: testVelocity{}})
+			require.ErrorIs(t, err, ErrComponentDataMismatch)
+			err = m.AddComponentToEntity(e2, Component{Type: velocities.Name()})
+			require.ErrorIs(t, err, ErrComponentDataMismatch)
+			require.False(t, velocities.Has(e2))
+		}
+
+		t.Log("Remove component - succeeds")
+		{
+			require.NoError(t, positions.Remove(e1))
+			require.False(t, positions.Has(e1))
+			require.True(t, velocities.Has(e1))
+			p, err := positions.Get(e2)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 5, Y: 5}, *p)
+		}
+
+		t.Log("Use deleted entity - fails")
+		{
+			require.NoError(t, m.DeleteEntity(e2))
+			require.False(t, positions.Has(e2))
+			_, err := positions.Get(e2)
+			require.ErrorIs(t, err, ErrStaleEntity)
+			require.ErrorIs(t, positions.Set(e2, testPosition{}), ErrStaleEntity)
+			require.ErrorIs(t, positions.Remove(e2), ErrStaleEntity)
+		}
+	}
+}
+
+func Test_TypedFunctions(t *testing.T) {
+	m := NewManager()
+	e := m.CreateEntity()
+
+	t.Log("Use type that is not registered - fails")
+	{
+		_, err := Get[testPosition](m, e)
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		require.False(t, Has[testPosition](m, e))
+		require.ErrorIs(t, Remove[testPosition](m, e), ErrComponentTypeNotFound)
+	}
+
+	t.Log("Set type that is not registered - registers it")
+	{
+		require.NoError(t, Set(m, e, testPosition{X: 1, Y: 2}))
+		require.True(t, Has[testPosition](m, e))
+		p, err := Get[testPosition](m, e)
+		require.NoError(t, err)
+		require.Equal(t, testPosition{X: 1, Y: 2}, *p)
+
+		s, err := Store[testPosition](m)
+		require.NoError(t, err)
+		require.Equal(t, "ecs.testPosition", s.Name())
+	}
+
+	t.Log("Remove component - succeeds")
+	{
+		require.NoError(t, Remove[testPosition](m, e))
+		require.False(t, Has[testPosition](m, e))
+	}
+}
+
+func Test_UntypedComponentData(t *testing.T) {
+	m := NewManager()
+	e := m.CreateEntity()
+
+	t.Log("Add untyped component without data - succeeds")
+	{
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Tag"}))
+		c, err := m.GetComponentOfEntity(e, "Tag")
+		require.NoError(t, err)
+		require.Nil(t, c.Data)
+	}
+
+	t.Log("Replace untyped component with data of another Go type - succeeds")
+	{
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Tag", Data: 42}))
+		c, err := m.GetComponentOfEntity(e, "Tag")
+		require.NoError(t, err)
+		require.Equal(t, 42, c.Data)
+	}
+}
@@ -6,33 +6,31 @@ import (
 	"gonum.org/v1/gonum/spatial/r2"
 )
 
-// TODO: Better error handling, don't crash loop for error
-func MovementSystem(m *ecs.Manager, deltaT float64) error {
-	entities, err := m.GetEntitiesWithComponents([]string{"Vector2", "Velocity2D"})
+// MovementSystem moves the entities with a Vector2 position by their Velocity2D every update
+type MovementSystem struct {
+	query *ecs.Query2[r2.Vec, r2.Vec]
+}
 
+// NewMovementSystem builds and registers the query of the system once. Positions registered with
+// components.RegisterVector2 are changed in place, others are copied out and stored back. It returns
+// ErrComponentTypeNotFound if Vector2 or Velocity2D is not known yet
+func NewMovementSystem(m *ecs.Manager) (*MovementSystem, error) {
+	// The entities move independently of each other, so the order they are visited in does not matter
+	builder := m.NewQuery().OrderBy(ecs.StorageOrder)
+	q, err := ecs.NewQuery2[r2.Vec, r2.Vec](builder, ecs.Mutable("Vector2"), ecs.ReadOnly("Velocity2D"))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	q.Query().Register()
+	return &MovementSystem{query: q}, nil
+}
 
-	for e, c := range entities {
-		vector, err := ecs.GetComponentData[r2.Vec](c, "Vector2")
-		if err != nil {
-			return err
-		}
-
-		velocity, err := ecs.GetComponentData[r2.Vec](c, "Velocity2D")
-		if err != nil {
-			return err
-		}
-
+// Update moves every entity by its velocity over deltaT
+func (s *MovementSystem) Update(deltaT float64) error {
+	// Positions that do not move keep their value, so change detection does not report them as changed
+	return s.query.Each(func(_ ecs.Entity, vector *r2.Vec, velocity *r2.Vec) bool {
 		vector.X += velocity.X * deltaT
 		vector.Y += velocity.Y * deltaT
-
-		m.AddComponentToEntity(e, ecs.Component{
-			Type: "Vector2",
-			Data: *vector,
-		})
-	}
-
-	return nil
+		return true
+	})
 }
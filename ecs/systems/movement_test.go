@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"go-ecs/ecs"
+	"go-ecs/ecs/components"
 
 	"github.com/stretchr/testify/require"
 	"gonum.org/v1/gonum/spatial/r2"
@@ -25,7 +26,9 @@ func Test_MovementSystem(t *testing.T) {
 
 	// Run the MovementSystem
 	deltaT := 1.0
-	require.NoError(t, MovementSystem(m, deltaT))
+	system, err := NewMovementSystem(m)
+	require.NoError(t, err)
+	require.NoError(t, system.Update(deltaT))
 
 	// Check the updated position
 	vector, err := m.GetComponentOfEntity(entity, "Vector2")
@@ -36,6 +39,78 @@ func Test_MovementSystem(t *testing.T) {
 	require.Equal(t, expectedVector, actualVector)
 }
 
+func Test_MovementSystem_SparseVelocity(t *testing.T) {
+	m := ecs.NewManager()
+	require.NoError(t, m.RegisterComponentType("Velocity2D", ecs.SparseSetStorage))
+
+	moving := m.CreateEntity()
+	m.AddComponentToEntity(moving, ecs.Component{
+		Type: "Vector2",
+		Data: r2.Vec{X: 0, Y: 0},
+	})
+	m.AddComponentToEntity(moving, ecs.Component{
+		Type: "Velocity2D",
+		Data: r2.Vec{X: 1, Y: 2},
+	})
+	still := m.CreateEntity()
+	m.AddComponentToEntity(still, ecs.Component{
+		Type: "Vector2",
+		Data: r2.Vec{X: 5, Y: 5},
+	})
+
+	system, err := NewMovementSystem(m)
+	require.NoError(t, err)
+	require.NoError(t, system.Update(0.5))
+
+	vector, err := m.GetComponentOfEntity(moving, "Vector2")
+	require.NoError(t, err)
+	actualVector, err := ecs.GetDataAsType[r2.Vec](vector)
+	require.NoError(t, err)
+	require.Equal(t, r2.Vec{X: 0.5, Y: 1}, actualVector)
+
+	vector, err = m.GetComponentOfEntity(still, "Vector2")
+	require.NoError(t, err)
+	actualVector, err = ecs.GetDataAsType[r2.Vec](vector)
+	require.NoError(t, err)
+	require.Equal(t, r2.Vec{X: 5, Y: 5}, actualVector)
+}
+
+func Test_MovementSystem_ChangeDetection(t *testing.T) {
+	m := ecs.NewManager()
+
+	moving := m.CreateEntity()
+	m.AddComponentToEntity(moving, ecs.Component{
+		Type: "Vector2",
+		Data: r2.Vec{X: 0, Y: 0},
+	})
+	m.AddComponentToEntity(moving, ecs.Component{
+		Type: "Velocity2D",
+		Data: r2.Vec{X: 1, Y: 0},
+	})
+	still := m.CreateEntity()
+	m.AddComponentToEntity(still, ecs.Component{
+		Type: "Vector2",
+		Data: r2.Vec{X: 5, Y: 5},
+	})
+	m.AddComponentToEntity(still, ecs.Component{
+		Type: "Velocity2D",
+		Data: r2.Vec{X: 0, Y: 0},
+	})
+
+	// The renderer has seen the initial positions
+	system, err := NewMovementSystem(m)
+	require.NoError(t, err)
+	var render ecs.SystemTicks
+	render.Begin(m)
+	require.NoError(t, system.Update(1.0))
+
+	render.Begin(m)
+	changed, err := m.GetChangedEntitiesWithComponents([]string{"Vector2"}, &render, ecs.ChangedType("Vector2"))
+	require.NoError(t, err)
+	require.Len(t, changed, 1)
+	require.Contains(t, changed, moving)
+}
+
 func Test_MovementSystem_NoVelocity(t *testing.T) {
 	m := ecs.NewManager()
 
@@ -46,9 +121,8 @@ func Test_MovementSystem_NoVelocity(t *testing.T) {
 		Data: r2.Vec{X: 0, Y: 0},
 	})
 
-	// Run the MovementSystem
-	deltaT := 1.0
-	err := MovementSystem(m, deltaT)
+	// Build the MovementSystem
+	_, err := NewMovementSystem(m)
 	require.ErrorIs(t, err, ecs.ErrComponentTypeNotFound)
 }
 
@@ -156,14 +230,16 @@ func Test_MovementSystem_MoveInSequence(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			m := ecs.NewManager()
+			_, err := components.RegisterVector2(m)
+			require.NoError(t, err)
+			require.NoError(t, m.RegisterComponentType("Velocity2D", ecs.TableStorage))
+			system, err := NewMovementSystem(m)
+			require.NoError(t, err)
 
 			entities := make([]ecs.Entity, len(tt.startPositions))
 			for i, startPosition := range tt.startPositions {
 				entity := m.CreateEntity()
-				m.AddComponentToEntity(entity, ecs.Component{
-					Type: "Vector2",
-					Data: startPosition,
-				})
+				require.NoError(t, m.AddComponentToEntity(entity, components.Vector2(startPosition.X, startPosition.Y)))
 				entities[i] = entity
 			}
 
@@ -176,7 +252,7 @@ func Test_MovementSystem_MoveInSequence(t *testing.T) {
 				}
 
 				deltaT := 1.0
-				require.NoError(t, MovementSystem(m, deltaT))
+				require.NoError(t, system.Update(deltaT))
 			}
 
 			for i, entity := range entities {
@@ -191,3 +267,37 @@ func Test_MovementSystem_MoveInSequence(t *testing.T) {
 		})
 	}
 }
+
+func Test_MovementSystem_RegisteredPosition(t *testing.T) {
+	m := ecs.NewManager()
+	positions, err := components.RegisterVector2(m)
+	require.NoError(t, err)
+
+	entity := m.CreateEntity()
+	require.NoError(t, positions.Set(entity, r2.Vec{X: 1, Y: 1}))
+	m.AddComponentToEntity(entity, ecs.Component{
+		Type: "Velocity2D",
+		Data: r2.Vec{X: 2, Y: -2},
+	})
+
+	system, err := NewMovementSystem(m)
+	require.NoError(t, err)
+	require.NoError(t, system.Update(0.5))
+
+	// Positions stored as r2.Vec are updated in place
+	position, err := positions.Get(entity)
+	require.NoError(t, err)
+	require.Equal(t, r2.Vec{X: 2, Y: 0}, *position)
+
+	// Positions are not boxed, so updates allocate as much for many entities as for one
+	update := func() {
+		require.NoError(t, system.Update(0.5))
+	}
+	allocs := testing.AllocsPerRun(10, update)
+	for range 100 {
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, components.Vector2(0, 0)))
+		require.NoError(t, m.AddComponentToEntity(e, ecs.Component{Type: "Velocity2D", Data: r2.Vec{X: 1}}))
+	}
+	require.Equal(t, allocs, testing.AllocsPerRun(10, update))
+}
@@ -0,0 +1,93 @@
+package ecs
+
+import (
+	"errors"
+)
+
+var ErrTagTypeMismatch = errors.New("component type is not a tag")
+
+// RegisterTag selects the storage used for the given tag, tags that are added and removed often are best kept in
+// SparseSetStorage. Tags are components without data: in TableStorage they only take the change ticks of each entity,
+// see Manager.ChangeTick. It returns ErrTagTypeMismatch if the name is used by a component type, and
+// ErrStorageTypeMismatch if the tag is already known with a different storage type
+func (m *Manager) RegisterTag(tag string, storage StorageType) error {
+	info, ok := m.registry.byName(tag)
+	if !ok {
+		m.registerTag(tag, storage)
+		return nil
+	}
+	if !info.tag {
+		return ErrTagTypeMismatch
+	}
+	if info.Storage != storage {
+		return ErrStorageTypeMismatch
+	}
+	return nil
+}
+
+func (m *Manager) registerTag(tag string, storage StorageType) *componentInfo {
+	info := newComponentInfo[struct{}](tag, false, storage)
+	info.tag = true
+	return m.registerComponent(info)
+}
+
+// tagInfo returns the registry entry of the tag, or false if the tag is unknown
+func (m *Manager) tagInfo(tag string) (*componentInfo, bool, error) {
+	info, ok := m.registry.byName(tag)
+	if !ok {
+		return nil, false, nil
+	}
+	if !info.tag {
+		return nil, false, ErrTagTypeMismatch
+	}
+	return info, true, nil
+}
+
+// AddTag adds the tag to the entity, tags that are not known yet are registered with TableStorage. Adding a tag the
+// entity already has does nothing
+func (m *Manager) AddTag(entity Entity, tag string) error {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	info, ok, err := m.tagInfo(tag)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		info = m.registerTag(tag, TableStorage)
+	}
+	if m.entities[entity.Index()].signature.Has(info.ID) {
+		return nil
+	}
+	m.setComponent(entity, info, struct{}{})
+	m.runHooks()
+	return nil
+}
+
+// RemoveTag removes the tag from the entity, it returns ErrComponentNotFound if the entity does not have the tag
+func (m *Manager) RemoveTag(entity Entity, tag string) error {
+	if err := m.checkEntity(entity); err != nil {
+		return err
+	}
+	info, ok, err := m.tagInfo(tag)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrComponentTypeNotFound
+	}
+	if !m.removeComponent(entity, info) {
+		return ErrComponentNotFound
+	}
+	m.runHooks()
+	return nil
+}
+
+// HasTag returns true if the entity is alive and has the tag
+func (m *Manager) HasTag(entity Entity, tag string) bool {
+	info, ok, err := m.tagInfo(tag)
+	if err != nil || !ok || m.checkEntity(entity) != nil {
+		return false
+	}
+	return m.entities[entity.Index()].signature.Has(info.ID)
+}
@@ -0,0 +1,94 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AddTag(t *testing.T) {
+	t.Log("Add, check and remove tags - succeeds")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.False(t, m.HasTag(e, "Frozen"))
+		require.NoError(t, m.AddTag(e, "Frozen"))
+		require.NoError(t, m.AddTag(e, "Frozen"))
+		require.True(t, m.HasTag(e, "Frozen"))
+		info, err := m.Registry().Lookup("Frozen")
+		require.NoError(t, err)
+		require.Equal(t, uintptr(0), info.Size)
+
+		require.NoError(t, m.RemoveTag(e, "Frozen"))
+		require.False(t, m.HasTag(e, "Frozen"))
+		require.ErrorIs(t, m.RemoveTag(e, "Frozen"), ErrComponentNotFound)
+		require.ErrorIs(t, m.RemoveTag(e, "Unknown"), ErrComponentTypeNotFound)
+	}
+
+	t.Log("Use component type as tag - fails")
+	{
+		m := NewManager()
+		e := m.CreateEntity()
+		require.NoError(t, m.AddComponentToEntity(e, Component{Type: "Health", Data: 100}))
+		require.ErrorIs(t, m.AddTag(e, "Health"), ErrTagTypeMismatch)
+		require.ErrorIs(t, m.RemoveTag(e, "Health"), ErrTagTypeMismatch)
+		require.ErrorIs(t, m.RegisterTag("Health", TableStorage), ErrTagTypeMismatch)
+		require.False(t, m.HasTag(e, "Health"))
+	}
+
+	t.Log("Register tag with a different storage - fails")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterTag("Player", SparseSetStorage))
+		require.NoError(t, m.RegisterTag("Player", SparseSetStorage))
+		require.ErrorIs(t, m.RegisterTag("Player", TableStorage), ErrStorageTypeMismatch)
+	}
+
+	t.Log("Add and remove tags - does not allocate per entity")
+	{
+		for _, storage := range []StorageType{TableStorage, SparseSetStorage} {
+			m := NewManager()
+			require.NoError(t, m.RegisterTag("Frozen", storage))
+			entities, err := m.CreateEntities(100)
+			require.NoError(t, err)
+			toggle := func() {
+				for _, e := range entities {
+					_ = m.AddTag(e, "Frozen")
+				}
+				for _, e := range entities {
+					_ = m.RemoveTag(e, "Frozen")
+				}
+			}
+			toggle()
+			require.Zero(t, testing.AllocsPerRun(10, toggle))
+		}
+	}
+}
+
+func Test_GetEntitiesWithComponentsFiltered(t *testing.T) {
+	t.Log("Query with and without tags - succeeds")
+	{
+		m := NewManager()
+		require.NoError(t, m.RegisterTag("Frozen", SparseSetStorage))
+		entities, err := m.SpawnBatch(4, Component{Type: "Health", Data: 100})
+		require.NoError(t, err)
+		require.NoError(t, m.AddTag(entities[0], "Player"))
+		require.NoError(t, m.AddTag(entities[1], "Enemy"))
+		require.NoError(t, m.AddTag(entities[2], "Enemy"))
+		require.NoError(t, m.AddTag(entities[2], "Frozen"))
+
+		result, err := m.GetEntitiesWithComponentsFiltered([]string{"Health"}, []string{"Enemy"}, []string{"Frozen", "Unknown"})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Len(t, result[entities[1]], 1)
+		require.Equal(t, 100, result[entities[1]][0].Data)
+
+		result, err = m.GetEntitiesWithComponentsFiltered([]string{"Health"}, nil, []string{"Player", "Enemy"})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Contains(t, result, entities[3])
+
+		_, err = m.GetEntitiesWithComponentsFiltered([]string{"Health"}, []string{"Unknown"}, nil)
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+	}
+}
@@ -0,0 +1,306 @@
+package ecs
+
+import (
+	"errors"
+	"reflect"
+)
+
+var ErrDuplicateQueryTerm = errors.New("typed query fetches the same component type more than once")
+
+// Term names a component type fetched by a typed query, and whether the query changes its components
+type Term struct {
+	componentType string
+	write         bool
+}
+
+// ReadOnly fetches the components of the type for reading. For types registered with Register the pointers point into
+// the storage of the Manager and must not be written through, for other types they point to a copy
+func ReadOnly(componentType string) Term {
+	return Term{componentType: componentType}
+}
+
+// Mutable fetches the components of the type for reading and writing, changes made through the pointers are stored.
+// Components whose value differs once the iteration step is done are marked as changed, components of Go types that
+// cannot be compared with ==, or that hold interfaces, are always marked
+func Mutable(componentType string) Term {
+	return Term{componentType: componentType, write: true}
+}
+
+// termAccess fetches the components of a term of a typed query as *T. Components of types whose data is stored as T
+// are accessed in place, components of types accepting data of any Go type are copied out, and stored back if they are
+// mutable and have changed
+type termAccess[T any] struct {
+	info  *componentInfo
+	write bool
+	// direct is true if the columns of the type are typedColumn[T], otherwise they are typedColumn[any] or hold the
+	// targets of relations, which are copied
+	direct bool
+	// comparable is true if values of T can be compared with == without panicking, so that only changed components are
+	// marked
+	comparable bool
+
+	// column and row locate the current component, value holds a copy of it unless direct is true. archetype is the
+	// archetype of column for types in table storage
+	archetype *archetype
+	column    column
+	typed     *typedColumn[T]
+	row       int
+	ptr       *T
+	value     T
+	// old holds the component as it was fetched, to find out whether it has changed
+	old T
+}
+
+func newTermAccess[T any](m *Manager, term Term) (termAccess[T], error) {
+	info, ok := m.registry.byName(term.componentType)
+	if !ok {
+		return termAccess[T]{}, ErrComponentTypeNotFound
+	}
+	if term.write && info.relation {
+		return termAccess[T]{}, ErrRelationTypeMismatch
+	}
+	goType := reflect.TypeFor[T]()
+	if info.Type != goType && info.Type != reflect.TypeFor[any]() {
+		return termAccess[T]{}, ErrComponentDataMismatch
+	}
+	return termAccess[T]{
+		info:       info,
+		write:      term.write,
+		direct:     info.Type == goType && !info.relation,
+		comparable: safelyComparable(goType),
+	}, nil
+}
+
+// safelyComparable returns whether values of the type can be compared with == without panicking. Comparing interface
+// values panics if they hold values of a type that cannot be compared, so types holding an interface, directly or in
+// a field or an array element, are not safely comparable
+func safelyComparable(t reflect.Type) bool {
+	if !t.Comparable() {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return false
+	case reflect.Array:
+		return safelyComparable(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if !safelyComparable(t.Field(i).Type) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// fetch points ptr to the component of the entity, which must have it. It returns ErrComponentDataMismatch if the data
+// of a type accepting any Go type is not of type T
+func (t *termAccess[T]) fetch(a *archetype, row int, entity Entity) error {
+	if t.info.set != nil {
+		row = t.info.set.dense(entity)
+		if t.column == nil {
+			t.setColumn(t.info.set.column)
+		}
+	} else if a != t.archetype {
+		t.archetype = a
+		t.setColumn(a.columns[a.column(t.info.ID)])
+	}
+	t.row = row
+
+	if t.direct {
+		t.ptr = &t.typed.data[row]
+	} else {
+		value, err := castData[T](componentData(t.info, t.column, row))
+		if err != nil {
+			return err
+		}
+		t.value = value
+		t.ptr = &t.value
+	}
+	if t.write && t.comparable {
+		t.old = *t.ptr
+	}
+	return nil
+}
+
+func (t *termAccess[T]) setColumn(c column) {
+	t.column = c
+	t.typed, _ = c.(*typedColumn[T])
+}
+
+// commit marks a mutable component as changed, if its value differs from the one fetched or if T cannot be compared,
+// and stores copies back. Changes made through pointers, slices or maps held by a comparable component are not
+// detected
+func (t *termAccess[T]) commit(m *Manager) {
+	if !t.write || t.comparable && any(t.old) == any(*t.ptr) {
+		return
+	}
+	if !t.direct {
+		// The data is of type T, so storing it cannot fail
+		_ = t.column.set(t.row, t.value)
+	}
+	t.column.markChanged(t.row, m.changeTick)
+}
+
+// buildTyped builds the query of the builder with the component types of the terms added as With terms, it returns
+// ErrDuplicateQueryTerm if two terms have the same type
+func (b *QueryBuilder) buildTyped(terms ...Term) (*Query, error) {
+	with := make([]string, 0, len(terms)+len(b.with))
+	for _, term := range terms {
+		for _, t := range with {
+			if t == term.componentType {
+				return nil, ErrDuplicateQueryTerm
+			}
+		}
+		with = append(with, term.componentType)
+	}
+	typed := *b
+	typed.with = append(with, b.with...)
+	return typed.Build()
+}
+
+// Query1 is a query yielding pointers to the components of type A of the matching entities
+type Query1[A any] struct {
+	query *Query
+	a     termAccess[A]
+}
+
+// NewQuery1 builds the query of the builder with the type of the term added as a With term, whose data must be of type
+// A. It returns ErrComponentDataMismatch if the type is registered with another Go type, and the errors of Build
+func NewQuery1[A any](builder *QueryBuilder, a Term) (*Query1[A], error) {
+	query, err := builder.buildTyped(a)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query1[A]{query: query}
+	if q.a, err = newTermAccess[A](query.m, a); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Query returns the underlying query, for example to register it
+func (q *Query1[A]) Query() *Query {
+	return q.query
+}
+
+// Each calls fn with every matching entity and pointers to its components in the order of the query, see Order, until
+// fn returns false. The pointers are only valid until fn returns, and components must not be added or removed while
+// iterating, record such changes in a CommandBuffer and apply it afterwards. It returns ErrComponentDataMismatch if the
+// data of a type accepting any Go type is not of the type of its term, which stops the iteration
+func (q *Query1[A]) Each(fn func(entity Entity, a *A) bool) error {
+	// The terms are copied so that iterations do not share their state
+	a := q.a
+	var err error
+	q.query.each(func(arch *archetype, row int, entity Entity) bool {
+		if err = a.fetch(arch, row, entity); err != nil {
+			return false
+		}
+		ok := fn(entity, a.ptr)
+		a.commit(q.query.m)
+		return ok
+	})
+	return err
+}
+
+// Query2 is a query yielding pointers to the components of types A and B of the matching entities
+type Query2[A, B any] struct {
+	query *Query
+	a     termAccess[A]
+	b     termAccess[B]
+}
+
+// NewQuery2 builds the query of the builder with the types of the terms added as With terms, see NewQuery1
+func NewQuery2[A, B any](builder *QueryBuilder, a Term, b Term) (*Query2[A, B], error) {
+	query, err := builder.buildTyped(a, b)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query2[A, B]{query: query}
+	if q.a, err = newTermAccess[A](query.m, a); err != nil {
+		return nil, err
+	}
+	if q.b, err = newTermAccess[B](query.m, b); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Query returns the underlying query, for example to register it
+func (q *Query2[A, B]) Query() *Query {
+	return q.query
+}
+
+// Each calls fn with every matching entity and pointers to its components, see Query1.Each
+func (q *Query2[A, B]) Each(fn func(entity Entity, a *A, b *B) bool) error {
+	a, b := q.a, q.b
+	var err error
+	q.query.each(func(arch *archetype, row int, entity Entity) bool {
+		if err = a.fetch(arch, row, entity); err != nil {
+			return false
+		}
+		if err = b.fetch(arch, row, entity); err != nil {
+			return false
+		}
+		ok := fn(entity, a.ptr, b.ptr)
+		a.commit(q.query.m)
+		b.commit(q.query.m)
+		return ok
+	})
+	return err
+}
+
+// Query3 is a query yielding pointers to the components of types A, B and C of the matching entities
+type Query3[A, B, C any] struct {
+	query *Query
+	a     termAccess[A]
+	b     termAccess[B]
+	c     termAccess[C]
+}
+
+// NewQuery3 builds the query of the builder with the types of the terms added as With terms, see NewQuery1
+func NewQuery3[A, B, C any](builder *QueryBuilder, a Term, b Term, c Term) (*Query3[A, B, C], error) {
+	query, err := builder.buildTyped(a, b, c)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query3[A, B, C]{query: query}
+	if q.a, err = newTermAccess[A](query.m, a); err != nil {
+		return nil, err
+	}
+	if q.b, err = newTermAccess[B](query.m, b); err != nil {
+		return nil, err
+	}
+	if q.c, err = newTermAccess[C](query.m, c); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Query returns the underlying query, for example to register it
+func (q *Query3[A, B, C]) Query() *Query {
+	return q.query
+}
+
+// Each calls fn with every matching entity and pointers to its components, see Query1.Each
+func (q *Query3[A, B, C]) Each(fn func(entity Entity, a *A, b *B, c *C) bool) error {
+	a, b, c := q.a, q.b, q.c
+	var err error
+	q.query.each(func(arch *archetype, row int, entity Entity) bool {
+		if err = a.fetch(arch, row, entity); err != nil {
+			return false
+		}
+		if err = b.fetch(arch, row, entity); err != nil {
+			return false
+		}
+		if err = c.fetch(arch, row, entity); err != nil {
+			return false
+		}
+		ok := fn(entity, a.ptr, b.ptr, c.ptr)
+		a.commit(q.query.m)
+		b.commit(q.query.m)
+		c.commit(q.query.m)
+		return ok
+	})
+	return err
+}
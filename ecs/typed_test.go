@@ -0,0 +1,206 @@
+package ecs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestTypedManager creates three entities with a Position stored as testPosition, a Velocity accepting any data and
+// a Mass in sparse set storage. The first entity does not move
+func newTestTypedManager(t *testing.T) (*Manager, *ComponentStore[testPosition], []Entity) {
+	t.Helper()
+	m := NewManager()
+	positions, err := Register[testPosition](m, WithName("Position"))
+	require.NoError(t, err)
+	require.NoError(t, m.RegisterComponentType("Mass", SparseSetStorage))
+	entities, err := m.SpawnBatch(3, Component{Type: "Position", Data: testPosition{X: 1, Y: 1}})
+	require.NoError(t, err)
+	for i, entity := range entities {
+		require.NoError(t, m.AddComponentToEntity(entity, Component{Type: "Velocity", Data: testVelocity{X: float64(i)}}))
+	}
+	require.NoError(t, m.AddComponentToEntity(entities[2], Component{Type: "Mass", Data: 2.0}))
+	return m, positions, entities
+}
+
+func Test_TypedQuery(t *testing.T) {
+	t.Log("Mutate typed components in place - marks changed components")
+	{
+		m, positions, entities := newTestTypedManager(t)
+		q, err := NewQuery2[testPosition, testVelocity](m.NewQuery(), Mutable("Position"), ReadOnly("Velocity"))
+		require.NoError(t, err)
+
+		var system SystemTicks
+		system.Begin(m)
+		system.Begin(m)
+		count := 0
+		require.NoError(t, q.Each(func(entity Entity, position *testPosition, velocity *testVelocity) bool {
+			count++
+			position.X += velocity.X * 2
+			return true
+		}))
+		require.Equal(t, 3, count)
+		for i, entity := range entities {
+			position, err := positions.Get(entity)
+			require.NoError(t, err)
+			require.Equal(t, testPosition{X: 1 + float64(i)*2, Y: 1}, *position)
+		}
+
+		system.Begin(m)
+		changed, err := m.GetChangedEntitiesWithComponents(nil, &system, ChangedType("Position"))
+		require.NoError(t, err)
+		require.Len(t, changed, 2)
+		require.NotContains(t, changed, entities[0])
+		changed, err = m.GetChangedEntitiesWithComponents(nil, &system, ChangedType("Velocity"))
+		require.NoError(t, err)
+		require.Empty(t, changed)
+	}
+
+	t.Log("Mutate components accepting any data - stores them back")
+	{
+		m, _, entities := newTestTypedManager(t)
+		q, err := NewQuery1[testVelocity](m.NewQuery(), Mutable("Velocity"))
+		require.NoError(t, err)
+		require.NoError(t, q.Each(func(entity Entity, velocity *testVelocity) bool {
+			velocity.Y = 5
+			return true
+		}))
+		c, err := m.GetComponentOfEntity(entities[1], "Velocity")
+		require.NoError(t, err)
+		require.Equal(t, testVelocity{X: 1, Y: 5}, c.Data)
+	}
+
+	t.Log("Write read only components accepting any data - is discarded")
+	{
+		m, _, entities := newTestTypedManager(t)
+		q, err := NewQuery1[testVelocity](m.NewQuery(), ReadOnly("Velocity"))
+		require.NoError(t, err)
+		require.NoError(t, q.Each(func(entity Entity, velocity *testVelocity) bool {
+			velocity.Y = 5
+			return true
+		}))
+		c, err := m.GetComponentOfEntity(entities[1], "Velocity")
+		require.NoError(t, err)
+		require.Equal(t, testVelocity{X: 1}, c.Data)
+	}
+
+	t.Log("Query three types with builder terms - succeeds")
+	{
+		m, _, entities := newTestTypedManager(t)
+		require.NoError(t, m.AddTag(entities[1], "Frozen"))
+		require.NoError(t, m.AddComponentToEntity(entities[1], Component{Type: "Mass", Data: 1.0}))
+		q, err := NewQuery3[float64, testPosition, testVelocity](m.NewQuery().Without("Frozen"), Mutable("Mass"), ReadOnly("Position"), ReadOnly("Velocity"))
+		require.NoError(t, err)
+		q.Query().Register()
+		for range 2 {
+			visited := make([]Entity, 0)
+			require.NoError(t, q.Each(func(entity Entity, mass *float64, position *testPosition, velocity *testVelocity) bool {
+				visited = append(visited, entity)
+				*mass += position.X + velocity.X
+				return true
+			}))
+			require.Equal(t, []Entity{entities[2]}, visited)
+		}
+		c, err := m.GetComponentOfEntity(entities[2], "Mass")
+		require.NoError(t, err)
+		require.Equal(t, 8.0, c.Data)
+	}
+
+	t.Log("Stop iteration early - succeeds")
+	{
+		m, _, _ := newTestTypedManager(t)
+		q, err := NewQuery1[testPosition](m.NewQuery(), ReadOnly("Position"))
+		require.NoError(t, err)
+		count := 0
+		require.NoError(t, q.Each(func(entity Entity, position *testPosition) bool {
+			count++
+			return false
+		}))
+		require.Equal(t, 1, count)
+	}
+
+	t.Log("Mutate components that cannot be compared - marks all of them")
+	{
+		m := NewManager()
+		entities, err := m.SpawnBatch(2, Component{Type: "Path", Data: []int{1}})
+		require.NoError(t, err)
+		q, err := NewQuery1[[]int](m.NewQuery(), Mutable("Path"))
+		require.NoError(t, err)
+
+		var system SystemTicks
+		system.Begin(m)
+		system.Begin(m)
+		require.NoError(t, q.Each(func(entity Entity, path *[]int) bool {
+			if entity == entities[0] {
+				*path = append(*path, 2)
+			}
+			return true
+		}))
+		system.Begin(m)
+		changed, err := m.GetChangedEntitiesWithComponents([]string{"Path"}, &system, ChangedType("Path"))
+		require.NoError(t, err)
+		require.Len(t, changed, 2)
+		require.Equal(t, []int{1, 2}, changed[entities[0]][0].Data)
+	}
+
+	t.Log("Mutate components holding interfaces - marks all of them")
+	{
+		type wrapper struct {
+			X any
+		}
+		m := NewManager()
+		entities, err := m.SpawnBatch(2, Component{Type: "Wrapper", Data: wrapper{X: []int{1}}})
+		require.NoError(t, err)
+		q, err := NewQuery1[wrapper](m.NewQuery(), Mutable("Wrapper"))
+		require.NoError(t, err)
+
+		var system SystemTicks
+		system.Begin(m)
+		system.Begin(m)
+		require.NoError(t, q.Each(func(entity Entity, w *wrapper) bool {
+			if entity == entities[0] {
+				w.X = []int{2}
+			}
+			return true
+		}))
+		system.Begin(m)
+		changed, err := m.GetChangedEntitiesWithComponents([]string{"Wrapper"}, &system, ChangedType("Wrapper"))
+		require.NoError(t, err)
+		require.Len(t, changed, 2)
+		require.Equal(t, wrapper{X: []int{2}}, changed[entities[0]][0].Data)
+
+		require.False(t, safelyComparable(reflect.TypeFor[[2]wrapper]()))
+		require.False(t, safelyComparable(reflect.TypeFor[struct{ W struct{ E error } }]()))
+		require.True(t, safelyComparable(reflect.TypeFor[struct{ P *wrapper }]()))
+	}
+
+	t.Log("Build typed queries - fails")
+	{
+		m, _, _ := newTestTypedManager(t)
+		_, err := NewQuery2[testPosition, testPosition](m.NewQuery(), ReadOnly("Position"), Mutable("Position"))
+		require.ErrorIs(t, err, ErrDuplicateQueryTerm)
+		_, err = NewQuery1[testVelocity](m.NewQuery(), ReadOnly("Position"))
+		require.ErrorIs(t, err, ErrComponentDataMismatch)
+		_, err = NewQuery1[testVelocity](m.NewQuery(), ReadOnly("Unknown"))
+		require.ErrorIs(t, err, ErrComponentTypeNotFound)
+		_, err = NewQuery1[testPosition](m.NewQuery().Without("Position"), ReadOnly("Position"))
+		require.ErrorIs(t, err, ErrContradictoryQuery)
+		require.NoError(t, m.RegisterRelation("ChildOf"))
+		_, err = NewQuery1[[]Entity](m.NewQuery(), Mutable("ChildOf"))
+		require.ErrorIs(t, err, ErrRelationTypeMismatch)
+		_, err = NewQuery1[[]Entity](m.NewQuery(), ReadOnly("ChildOf"))
+		require.NoError(t, err)
+	}
+
+	t.Log("Iterate components accepting any data of another type - fails")
+	{
+		m, _, entities := newTestTypedManager(t)
+		require.NoError(t, m.AddComponentToEntity(entities[1], Component{Type: "Velocity", Data: 3}))
+		q, err := NewQuery1[testVelocity](m.NewQuery(), Mutable("Velocity"))
+		require.NoError(t, err)
+		require.ErrorIs(t, q.Each(func(entity Entity, velocity *testVelocity) bool {
+			return true
+		}), ErrComponentDataMismatch)
+	}
+}